@@ -1,5 +1,17 @@
 module github.com/thiagomendes/benchmark-mcp-servers/go-server
 
-go 1.23
+go 1.23.0
 
-require github.com/modelcontextprotocol/go-sdk v1.2.0
+require (
+	github.com/google/jsonschema-go v0.3.0
+	github.com/modelcontextprotocol/go-sdk v1.2.0
+	golang.org/x/net v0.35.0
+	golang.org/x/sys v0.30.0
+	golang.org/x/time v0.10.0
+)
+
+require (
+	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	golang.org/x/oauth2 v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
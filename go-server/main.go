@@ -1,13 +1,52 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"container/list"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+	"unsafe"
 
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
+	"golang.org/x/time/rate"
 )
 
 // Input structures
@@ -17,175 +56,7626 @@ type FibonacciArgs struct {
 
 type FetchDataArgs struct {
 	Endpoint string `json:"endpoint"`
+	// UseCache serves repeated fetches of the same URL from the in-memory
+	// response cache while the entry is fresh
+	UseCache bool `json:"use_cache,omitempty"`
+	// FollowRedirects defaults to true; MaxRedirects defaults to 10, the
+	// net/http limit
+	FollowRedirects *bool `json:"follow_redirects,omitempty"`
+	MaxRedirects    int   `json:"max_redirects,omitempty"`
 }
 
 type ProcessDataArgs struct {
 	Data map[string]interface{} `json:"data"`
+	// PreciseNumbers keeps numbers as their original JSON literals instead of
+	// float64, so large integer IDs survive the round-trip unchanged
+	PreciseNumbers bool `json:"precise_numbers,omitempty"`
 }
 
 type DatabaseQueryArgs struct {
-	Query   string `json:"query"`
-	DelayMs int    `json:"delay_ms,omitempty"`
+	Query    string `json:"query"`
+	DelayMs  int    `json:"delay_ms,omitempty"`
+	RowCount int    `json:"row_count,omitempty"`
+	// LatencyProfile draws the delay from a named distribution around
+	// delay_ms (default 10ms): steady, bimodal or p99-spike
+	LatencyProfile string `json:"latency_profile,omitempty"`
+}
+
+type RegexCompileArgs struct {
+	Pattern    string `json:"pattern"`
+	Iterations int    `json:"iterations"`
+}
+
+type CacheOpArgs struct {
+	Op    string `json:"op"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+type JSONQueryArgs struct {
+	Data map[string]interface{} `json:"data"`
+	Path string                 `json:"path"`
+}
+
+type CollatzArgs struct {
+	Start int64 `json:"start"`
+}
+
+type LeakMemoryArgs struct {
+	KB int `json:"kb"`
+}
+
+type SpawnGoroutinesArgs struct {
+	Count int `json:"count"`
+}
+
+type PiDigitsArgs struct {
+	Digits int `json:"digits"`
+}
+
+type LockContentionArgs struct {
+	Goroutines int    `json:"goroutines"`
+	Iterations int    `json:"iterations"`
+	Primitive  string `json:"primitive"`
+}
+
+type EnqueueArgs struct {
+	Value     string `json:"value"`
+	TimeoutMs int    `json:"timeout_ms,omitempty"`
+}
+
+type DequeueArgs struct {
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+type StructMapArgs struct {
+	Data       map[string]interface{} `json:"data"`
+	Iterations int                    `json:"iterations"`
+}
+
+type TimeFormatArgs struct {
+	Input        string `json:"input"`
+	Layout       string `json:"layout"`
+	OutputLayout string `json:"output_layout"`
+	Iterations   int    `json:"iterations"`
+}
+
+type WalkFilesystemArgs struct {
+	Path       string `json:"path"`
+	MaxEntries int    `json:"max_entries"`
+}
+
+type BatchCall struct {
+	Tool string                 `json:"tool"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type BatchArgs struct {
+	Calls    []BatchCall `json:"calls"`
+	Parallel bool        `json:"parallel,omitempty"`
+}
+
+type BSTBenchmarkArgs struct {
+	Operations int   `json:"operations"`
+	Seed       int64 `json:"seed"`
+}
+
+type SortNumbersArgs struct {
+	Count      int    `json:"count"`
+	Seed       int64  `json:"seed"`
+	Algorithm  string `json:"algorithm"`
+	Instrument bool   `json:"instrument,omitempty"`
+}
+
+type EditDistanceArgs struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+type EnvInfoArgs struct{}
+
+type MathBenchArgs struct {
+	Iterations int    `json:"iterations"`
+	Operation  string `json:"operation"`
+}
+
+type FactorizeArgs struct {
+	N        int64 `json:"n"`
+	Parallel bool  `json:"parallel,omitempty"`
+}
+
+type BoxingBenchArgs struct {
+	Iterations int `json:"iterations"`
+}
+
+type CopyBenchArgs struct {
+	Size       int    `json:"size"`
+	Iterations int    `json:"iterations"`
+	Mode       string `json:"mode"`
+}
+
+type HistogramArgs struct {
+	Values  []float64 `json:"values"`
+	Buckets int       `json:"buckets"`
+}
+
+type AppendBenchArgs struct {
+	Count       int  `json:"count"`
+	Preallocate bool `json:"preallocate,omitempty"`
+}
+
+type PipelineArgs struct {
+	Stages int `json:"stages"`
+	WorkMs int `json:"work_ms"`
+}
+
+type MarshalBenchArgs struct {
+	Depth      int `json:"depth"`
+	Breadth    int `json:"breadth"`
+	Iterations int `json:"iterations"`
+}
+
+type ContextBenchArgs struct {
+	Depth      int `json:"depth"`
+	Iterations int `json:"iterations"`
+}
+
+type DeferBenchArgs struct {
+	Iterations int  `json:"iterations"`
+	UseDefer   bool `json:"use_defer,omitempty"`
+}
+
+type MemoryAccessBenchArgs struct {
+	SizeMB  int    `json:"size_mb"`
+	Pattern string `json:"pattern"`
+}
+
+type MapChurnArgs struct {
+	Size       int   `json:"size"`
+	Operations int   `json:"operations"`
+	Seed       int64 `json:"seed"`
+}
+
+type LoadInfoArgs struct{}
+
+type ErrorBenchArgs struct {
+	Iterations int    `json:"iterations"`
+	Mode       string `json:"mode"`
+}
+
+type FetchParallelArgs struct {
+	Endpoints   []string `json:"endpoints"`
+	Concurrency int      `json:"concurrency"`
+}
+
+type ReflectBenchArgs struct {
+	Iterations int    `json:"iterations"`
+	Mode       string `json:"mode"`
+}
+
+type GenerateCSVArgs struct {
+	Rows int `json:"rows"`
+	Cols int `json:"cols"`
+}
+
+type ParseCSVArgs struct {
+	Data string `json:"data"`
+}
+
+type ClockBenchArgs struct {
+	Iterations int `json:"iterations"`
+}
+
+type RNGBenchArgs struct {
+	Iterations  int    `json:"iterations"`
+	Concurrency int    `json:"concurrency"`
+	Mode        string `json:"mode"`
+}
+
+type MandelbrotArgs struct {
+	Width        int  `json:"width"`
+	Height       int  `json:"height"`
+	MaxIter      int  `json:"max_iter"`
+	Parallel     bool `json:"parallel,omitempty"`
+	IncludeImage bool `json:"include_image,omitempty"`
+}
+
+type IOWriteBenchArgs struct {
+	Bytes    int  `json:"bytes"`
+	Buffered bool `json:"buffered,omitempty"`
+	// ToFile writes to a temporary file (removed afterwards) instead of io.Discard
+	ToFile bool `json:"to_file,omitempty"`
+}
+
+type CounterBenchArgs struct {
+	Goroutines int    `json:"goroutines"`
+	Increments int    `json:"increments"`
+	Strategy   string `json:"strategy"`
+}
+
+type BinarySerializeArgs struct {
+	Data       map[string]interface{} `json:"data"`
+	Format     string                 `json:"format"`
+	Iterations int                    `json:"iterations"`
+}
+
+type VectorSumArgs struct {
+	Size       int `json:"size"`
+	Iterations int `json:"iterations"`
+}
+
+type TreeTraversalArgs struct {
+	Nodes  int    `json:"nodes"`
+	Method string `json:"method"`
+}
+
+type InternBenchArgs struct {
+	Unique int  `json:"unique"`
+	Total  int  `json:"total"`
+	Intern bool `json:"intern,omitempty"`
+}
+
+type ShardedMapBenchArgs struct {
+	Shards     int `json:"shards"`
+	Goroutines int `json:"goroutines"`
+	Operations int `json:"operations"`
+}
+
+type ProfileToolArgs struct {
+	Tool string                 `json:"tool"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+type JSONDecodeBenchArgs struct {
+	Data string `json:"data"`
+	Mode string `json:"mode"`
+}
+
+type FinalizerBenchArgs struct {
+	Objects int `json:"objects"`
+}
+
+type BigMulBenchArgs struct {
+	Digits    int    `json:"digits"`
+	Algorithm string `json:"algorithm"`
+	Squarings int    `json:"squarings,omitempty"`
+}
+
+type PingPongArgs struct {
+	Rounds int `json:"rounds"`
+}
+
+type ByteParseBenchArgs struct {
+	Data       string `json:"data"`
+	Iterations int    `json:"iterations"`
+	Mode       string `json:"mode"`
+}
+
+type CacheSimArgs struct {
+	Capacity int    `json:"capacity"`
+	Accesses []int  `json:"accesses"`
+	Policy   string `json:"policy"`
+}
+
+type StrconvBenchArgs struct {
+	Count      int    `json:"count"`
+	Type       string `json:"type"`
+	Iterations int    `json:"iterations"`
+}
+
+type SyscallBenchArgs struct {
+	Iterations int `json:"iterations"`
+}
+
+type SortTypesArgs struct {
+	Count  int    `json:"count"`
+	Type   string `json:"type"`
+	Seed   int64  `json:"seed"`
+	Stable bool   `json:"stable,omitempty"`
+}
+
+type RWMutexBenchArgs struct {
+	Readers    int `json:"readers"`
+	Writers    int `json:"writers"`
+	Operations int `json:"operations"`
+}
+
+type FixtureJSONArgs struct {
+	SizeKB int   `json:"size_kb"`
+	Seed   int64 `json:"seed"`
+}
+
+type WorkerPoolBenchArgs struct {
+	Tasks   int `json:"tasks"`
+	Workers int `json:"workers"`
+	WorkMs  int `json:"work_ms"`
+}
+
+type SimulateErrorArgs struct {
+	Code string `json:"code"`
+}
+
+type MemcopyBenchArgs struct {
+	SizeMB     int `json:"size_mb"`
+	Iterations int `json:"iterations"`
 }
 
 // Output structures
+//
+// Every output carries schema_version so archived benchmark results stay
+// interpretable as these shapes evolve. Bump it whenever a field is renamed,
+// removed or changes meaning.
+const outputSchemaVersion = "1"
+
 type FibonacciOutput struct {
-	Input      int    `json:"input"`
-	Result     int    `json:"result"`
-	ServerType string `json:"server_type"`
+	Input         int    `json:"input"`
+	Result        int    `json:"result"`
+	ServerType    string `json:"server_type"`
+	SchemaVersion string `json:"schema_version"`
+}
+
+type FetchDataOutput struct {
+	URL            string `json:"url"`
+	StatusCode     int    `json:"status_code"`
+	ResponseTimeMs int64  `json:"response_time_ms"`
+	CacheHit       bool   `json:"cache_hit"`
+	CircuitOpen    bool   `json:"circuit_open"`
+	Redirects      int    `json:"redirects"`
+	Error          string `json:"error,omitempty"`
+	ErrorKind      string `json:"error_kind,omitempty"`
+	ServerType     string `json:"server_type"`
+	SchemaVersion  string `json:"schema_version"`
+}
+
+type ProcessDataOutput struct {
+	OriginalKeys    []string               `json:"original_keys"`
+	TransformedData map[string]interface{} `json:"transformed_data"`
+	NodesProcessed  int                    `json:"nodes_processed"`
+	Cancelled       bool                   `json:"cancelled,omitempty"`
+	ServerType      string                 `json:"server_type"`
+	SchemaVersion   string                 `json:"schema_version"`
+}
+
+type DatabaseOutput struct {
+	Query         string                   `json:"query"`
+	DelayMs       int                      `json:"delay_ms"`
+	Profile       string                   `json:"latency_profile,omitempty"`
+	RealizedMs    float64                  `json:"realized_delay_ms,omitempty"`
+	Timestamp     string                   `json:"timestamp"`
+	RowCount      int                      `json:"row_count"`
+	Rows          []map[string]interface{} `json:"rows,omitempty"`
+	ServerType    string                   `json:"server_type"`
+	SchemaVersion string                   `json:"schema_version"`
+}
+
+type RegexCompileOutput struct {
+	Pattern       string  `json:"pattern"`
+	Iterations    int     `json:"iterations"`
+	TotalTimeMs   float64 `json:"total_time_ms"`
+	PerCompileNs  float64 `json:"per_compile_ns"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type CacheOpOutput struct {
+	Op            string `json:"op"`
+	Key           string `json:"key"`
+	Value         string `json:"value,omitempty"`
+	Found         bool   `json:"found"`
+	LatencyNs     int64  `json:"latency_ns"`
+	ServerType    string `json:"server_type"`
+	SchemaVersion string `json:"schema_version"`
+}
+
+type JSONQueryOutput struct {
+	Path          string      `json:"path"`
+	Value         interface{} `json:"value"`
+	ElapsedMs     float64     `json:"elapsed_ms"`
+	ServerType    string      `json:"server_type"`
+	SchemaVersion string      `json:"schema_version"`
+}
+
+type CollatzOutput struct {
+	Start         int64   `json:"start"`
+	Steps         int     `json:"steps"`
+	MaxValue      int64   `json:"max_value"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type LeakMemoryOutput struct {
+	AddedKB         int    `json:"added_kb"`
+	TotalRetainedKB int64  `json:"total_retained_kb"`
+	Chunks          int    `json:"chunks"`
+	ServerType      string `json:"server_type"`
+	SchemaVersion   string `json:"schema_version"`
+}
+
+type SpawnGoroutinesOutput struct {
+	Count            int     `json:"count"`
+	ElapsedMs        float64 `json:"elapsed_ms"`
+	GoroutinesPerSec float64 `json:"goroutines_per_sec"`
+	Checksum         int64   `json:"checksum"`
+	ServerType       string  `json:"server_type"`
+	SchemaVersion    string  `json:"schema_version"`
+}
+
+type PiDigitsOutput struct {
+	Digits        int     `json:"digits"`
+	Pi            string  `json:"pi"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type LockContentionOutput struct {
+	Primitive     string  `json:"primitive"`
+	Goroutines    int     `json:"goroutines"`
+	Iterations    int     `json:"iterations"`
+	FinalCount    int64   `json:"final_count"`
+	ExpectedCount int64   `json:"expected_count"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	OpsPerSec     float64 `json:"ops_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type QueueOpOutput struct {
+	Op            string `json:"op"`
+	Value         string `json:"value,omitempty"`
+	Ok            bool   `json:"ok"`
+	Depth         int    `json:"depth"`
+	Capacity      int    `json:"capacity"`
+	LatencyNs     int64  `json:"latency_ns"`
+	ServerType    string `json:"server_type"`
+	SchemaVersion string `json:"schema_version"`
+}
+
+// StructMapRecord is the fixed target shape struct_map decodes into
+type StructMapRecord struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Email      string            `json:"email"`
+	Active     bool              `json:"active"`
+	Score      float64           `json:"score"`
+	Tags       []string          `json:"tags,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Address    struct {
+		Street  string `json:"street"`
+		City    string `json:"city"`
+		Country string `json:"country"`
+	} `json:"address"`
+}
+
+type StructMapOutput struct {
+	Iterations    int             `json:"iterations"`
+	PayloadBytes  int             `json:"payload_bytes"`
+	Record        StructMapRecord `json:"record"`
+	ElapsedMs     float64         `json:"elapsed_ms"`
+	PerIterNs     float64         `json:"per_iteration_ns"`
+	ServerType    string          `json:"server_type"`
+	SchemaVersion string          `json:"schema_version"`
+}
+
+type TimeFormatOutput struct {
+	Input         string  `json:"input"`
+	Result        string  `json:"result"`
+	Iterations    int     `json:"iterations"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	PerIterNs     float64 `json:"per_iteration_ns"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type WalkFilesystemOutput struct {
+	Path          string  `json:"path"`
+	Files         int     `json:"files"`
+	Dirs          int     `json:"dirs"`
+	TotalBytes    int64   `json:"total_bytes"`
+	Errors        int     `json:"errors"`
+	Truncated     bool    `json:"truncated"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type BatchCallResult struct {
+	Tool      string      `json:"tool"`
+	Ok        bool        `json:"ok"`
+	Result    interface{} `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ElapsedMs float64     `json:"elapsed_ms"`
+}
+
+type BatchOutput struct {
+	Results       []BatchCallResult `json:"results"`
+	Parallel      bool              `json:"parallel"`
+	Succeeded     int               `json:"succeeded"`
+	Failed        int               `json:"failed"`
+	TotalMs       float64           `json:"total_ms"`
+	ServerType    string            `json:"server_type"`
+	SchemaVersion string            `json:"schema_version"`
+}
+
+type BSTBenchmarkOutput struct {
+	Operations    int     `json:"operations"`
+	Seed          int64   `json:"seed"`
+	Inserts       int     `json:"inserts"`
+	Lookups       int     `json:"lookups"`
+	LookupHits    int     `json:"lookup_hits"`
+	Deletes       int     `json:"deletes"`
+	FinalNodes    int     `json:"final_nodes"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type SortNumbersOutput struct {
+	Algorithm    string `json:"algorithm"`
+	Count        int    `json:"count"`
+	Seed         int64  `json:"seed"`
+	Instrumented bool   `json:"instrumented"`
+	Comparisons  int64  `json:"comparisons"`
+	// Swaps counts element exchanges for quicksort and element writes for mergesort
+	Swaps         int64   `json:"swaps"`
+	Sorted        bool    `json:"sorted"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type EditDistanceOutput struct {
+	Distance      int     `json:"distance"`
+	TableRows     int     `json:"table_rows"`
+	TableCols     int     `json:"table_cols"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type EnvInfoOutput struct {
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	GoVersion  string `json:"go_version"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	// Cgroup limits are omitted when not running under a limiting cgroup
+	CgroupVersion    string            `json:"cgroup_version,omitempty"`
+	CPULimitCores    float64           `json:"cpu_limit_cores,omitempty"`
+	MemoryLimitBytes int64             `json:"memory_limit_bytes,omitempty"`
+	InContainer      bool              `json:"in_container"`
+	GOGC             string            `json:"gogc"`
+	GOMEMLIMIT       string            `json:"gomemlimit"`
+	GODEBUG          string            `json:"godebug"`
+	BuildSettings    map[string]string `json:"build_settings,omitempty"`
+	ServerType       string            `json:"server_type"`
+	SchemaVersion    string            `json:"schema_version"`
+}
+
+type MathBenchOutput struct {
+	Operation     string  `json:"operation"`
+	Iterations    int     `json:"iterations"`
+	Checksum      float64 `json:"checksum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	OpsPerSec     float64 `json:"ops_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type FactorizeOutput struct {
+	N             int64   `json:"n"`
+	Factors       []int64 `json:"factors"`
+	Parallel      bool    `json:"parallel"`
+	Workers       int     `json:"workers"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type BoxingBenchOutput struct {
+	Iterations    int     `json:"iterations"`
+	BoxedMs       float64 `json:"boxed_ms"`
+	TypedMs       float64 `json:"typed_ms"`
+	DiffMs        float64 `json:"diff_ms"`
+	BoxedNsPerOp  float64 `json:"boxed_ns_per_op"`
+	TypedNsPerOp  float64 `json:"typed_ns_per_op"`
+	BoxedAllocs   uint64  `json:"boxed_allocs"`
+	TypedAllocs   uint64  `json:"typed_allocs"`
+	Checksum      int64   `json:"checksum"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type CopyBenchOutput struct {
+	Mode          string  `json:"mode"`
+	Size          int     `json:"size"`
+	Iterations    int     `json:"iterations"`
+	BytesPerIter  int     `json:"bytes_per_iter"`
+	Checksum      int64   `json:"checksum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerIter     float64 `json:"ns_per_iter"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type HistogramOutput struct {
+	Count         int       `json:"count"`
+	Min           float64   `json:"min"`
+	Max           float64   `json:"max"`
+	Boundaries    []float64 `json:"boundaries"`
+	Counts        []int     `json:"counts"`
+	ElapsedMs     float64   `json:"elapsed_ms"`
+	ServerType    string    `json:"server_type"`
+	SchemaVersion string    `json:"schema_version"`
+}
+
+type AppendBenchOutput struct {
+	Count         int     `json:"count"`
+	Preallocate   bool    `json:"preallocate"`
+	Reallocations int     `json:"reallocations"`
+	FinalCap      int     `json:"final_cap"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerAppend   float64 `json:"ns_per_append"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type PipelineStage struct {
+	Stage     int     `json:"stage"`
+	ElapsedMs float64 `json:"elapsed_ms"`
+	Rounds    int     `json:"rounds"`
+	Checksum  uint64  `json:"checksum"`
+}
+
+type PipelineOutput struct {
+	Stages        []PipelineStage `json:"stages"`
+	TotalMs       float64         `json:"total_ms"`
+	Checksum      uint64          `json:"checksum"`
+	ServerType    string          `json:"server_type"`
+	SchemaVersion string          `json:"schema_version"`
+}
+
+type MarshalBenchOutput struct {
+	Depth         int     `json:"depth"`
+	Breadth       int     `json:"breadth"`
+	Nodes         int     `json:"nodes"`
+	Iterations    int     `json:"iterations"`
+	Bytes         int     `json:"bytes"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	MBPerSec      float64 `json:"mb_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type ContextBenchOutput struct {
+	Depth         int     `json:"depth"`
+	Iterations    int     `json:"iterations"`
+	Lookups       int64   `json:"lookups"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerIter     float64 `json:"ns_per_iter"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type DeferBenchOutput struct {
+	Iterations    int     `json:"iterations"`
+	UseDefer      bool    `json:"use_defer"`
+	Checksum      int64   `json:"checksum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerCall     float64 `json:"ns_per_call"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type MemoryAccessBenchOutput struct {
+	SizeMB        int     `json:"size_mb"`
+	Pattern       string  `json:"pattern"`
+	Elements      int     `json:"elements"`
+	Sum           int64   `json:"sum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerElement  float64 `json:"ns_per_element"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type MapChurnOutput struct {
+	Size          int     `json:"size"`
+	Operations    int     `json:"operations"`
+	Seed          int64   `json:"seed"`
+	Inserts       int     `json:"inserts"`
+	Deletes       int     `json:"deletes"`
+	FinalSize     int     `json:"final_size"`
+	KeySum        int64   `json:"key_sum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type LoadInfoOutput struct {
+	InFlightRequests int64   `json:"in_flight_requests"`
+	RecentAvgMs      float64 `json:"recent_avg_ms"`
+	RecentSamples    int     `json:"recent_samples"`
+	CPUPercent       float64 `json:"cpu_percent"`
+	CPUWindowMs      float64 `json:"cpu_window_ms"`
+	GOMAXPROCS       int     `json:"gomaxprocs"`
+	Goroutines       int     `json:"goroutines"`
+	MemoryDegraded   bool    `json:"memory_degraded"`
+	ServerType       string  `json:"server_type"`
+	SchemaVersion    string  `json:"schema_version"`
+}
+
+type ErrorBenchOutput struct {
+	Mode          string  `json:"mode"`
+	Iterations    int     `json:"iterations"`
+	Matched       int     `json:"matched"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerError    float64 `json:"ns_per_error"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type FetchParallelResult struct {
+	URL            string  `json:"url"`
+	StatusCode     int     `json:"status_code"`
+	ResponseTimeMs float64 `json:"response_time_ms"`
+	CircuitOpen    bool    `json:"circuit_open"`
+	Redirects      int     `json:"redirects"`
+	Error          string  `json:"error,omitempty"`
+	ErrorKind      string  `json:"error_kind,omitempty"`
+}
+
+type FetchParallelOutput struct {
+	Results       []FetchParallelResult `json:"results"`
+	Concurrency   int                   `json:"concurrency"`
+	Succeeded     int                   `json:"succeeded"`
+	Failed        int                   `json:"failed"`
+	WallTimeMs    float64               `json:"wall_time_ms"`
+	ServerType    string                `json:"server_type"`
+	SchemaVersion string                `json:"schema_version"`
+}
+
+type ReflectBenchOutput struct {
+	Mode          string  `json:"mode"`
+	Iterations    int     `json:"iterations"`
+	Checksum      int64   `json:"checksum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerIter     float64 `json:"ns_per_iter"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type GenerateCSVOutput struct {
+	Data          string  `json:"data"`
+	Rows          int     `json:"rows"`
+	Cols          int     `json:"cols"`
+	Bytes         int     `json:"bytes"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type CSVParseError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type ParseCSVOutput struct {
+	Rows          int             `json:"rows"`
+	Fields        int             `json:"fields"`
+	Columns       int             `json:"columns"`
+	ErrorCount    int             `json:"error_count"`
+	Errors        []CSVParseError `json:"errors,omitempty"`
+	ElapsedMs     float64         `json:"elapsed_ms"`
+	ServerType    string          `json:"server_type"`
+	SchemaVersion string          `json:"schema_version"`
+}
+
+type ClockBenchOutput struct {
+	Iterations    int     `json:"iterations"`
+	NanosSum      int64   `json:"nanos_sum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerCall     float64 `json:"ns_per_call"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type RNGBenchOutput struct {
+	Mode          string  `json:"mode"`
+	Iterations    int     `json:"iterations"`
+	Concurrency   int     `json:"concurrency"`
+	Checksum      int64   `json:"checksum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerNumber   float64 `json:"ns_per_number"`
+	OpsPerSec     float64 `json:"ops_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type MandelbrotOutput struct {
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+	MaxIter       int     `json:"max_iter"`
+	Workers       int     `json:"workers"`
+	Checksum      int64   `json:"checksum"`
+	Escaped       int     `json:"escaped"`
+	Image         []int   `json:"image,omitempty"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type IOWriteBenchOutput struct {
+	Bytes         int     `json:"bytes"`
+	Buffered      bool    `json:"buffered"`
+	Target        string  `json:"target"`
+	Writes        int     `json:"writes"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	MBPerSec      float64 `json:"mb_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type CounterBenchOutput struct {
+	Strategy      string  `json:"strategy"`
+	Goroutines    int     `json:"goroutines"`
+	Increments    int     `json:"increments"`
+	FinalCount    int64   `json:"final_count"`
+	ExpectedCount int64   `json:"expected_count"`
+	Correct       bool    `json:"correct"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	OpsPerSec     float64 `json:"ops_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type BinarySerializeOutput struct {
+	Format        string  `json:"format"`
+	Iterations    int     `json:"iterations"`
+	Bytes         int     `json:"bytes"`
+	EncodeMs      float64 `json:"encode_ms"`
+	DecodeMs      float64 `json:"decode_ms"`
+	NsPerEncode   float64 `json:"ns_per_encode"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type VectorSumOutput struct {
+	Size          int     `json:"size"`
+	Iterations    int     `json:"iterations"`
+	Sum           float64 `json:"sum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	GBPerSec      float64 `json:"gb_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type TreeTraversalOutput struct {
+	Nodes         int     `json:"nodes"`
+	Method        string  `json:"method"`
+	Visited       int     `json:"visited"`
+	Checksum      int64   `json:"checksum"`
+	BuildMs       float64 `json:"build_ms"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type InternBenchOutput struct {
+	Unique        int     `json:"unique"`
+	Total         int     `json:"total"`
+	Intern        bool    `json:"intern"`
+	Allocations   uint64  `json:"allocations"`
+	RetainedBytes int64   `json:"retained_bytes"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type ShardedMapBenchOutput struct {
+	Shards        int     `json:"shards"`
+	Goroutines    int     `json:"goroutines"`
+	Operations    int     `json:"operations"`
+	SingleMs      float64 `json:"single_ms"`
+	ShardedMs     float64 `json:"sharded_ms"`
+	Speedup       float64 `json:"speedup"`
+	Entries       int     `json:"entries"`
+	Correct       bool    `json:"correct"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type ProfileToolOutput struct {
+	Tool          string      `json:"tool"`
+	Ok            bool        `json:"ok"`
+	Result        interface{} `json:"result,omitempty"`
+	Error         string      `json:"error,omitempty"`
+	ElapsedMs     float64     `json:"elapsed_ms"`
+	ProfileBytes  int         `json:"profile_bytes"`
+	Profile       string      `json:"profile"`
+	ServerType    string      `json:"server_type"`
+	SchemaVersion string      `json:"schema_version"`
+}
+
+type JSONDecodeBenchOutput struct {
+	Mode          string  `json:"mode"`
+	Bytes         int     `json:"bytes"`
+	Values        int     `json:"values"`
+	Fields        int     `json:"fields"`
+	Allocations   uint64  `json:"allocations"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type FinalizerBenchOutput struct {
+	Objects       int     `json:"objects"`
+	FinalizersRan int64   `json:"finalizers_ran"`
+	AllocateMs    float64 `json:"allocate_ms"`
+	CollectMs     float64 `json:"collect_ms"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type BigMulBenchOutput struct {
+	Digits        int     `json:"digits"`
+	Algorithm     string  `json:"algorithm"`
+	Squarings     int     `json:"squarings,omitempty"`
+	ResultBits    int     `json:"result_bits"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type PingPongOutput struct {
+	Rounds        int     `json:"rounds"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerRound    float64 `json:"ns_per_round"`
+	RoundsPerSec  float64 `json:"rounds_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type ByteParseBenchOutput struct {
+	Mode          string  `json:"mode"`
+	Iterations    int     `json:"iterations"`
+	Fields        int     `json:"fields"`
+	Numeric       int     `json:"numeric"`
+	Sum           int64   `json:"sum"`
+	Allocations   uint64  `json:"allocations"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type CacheSimOutput struct {
+	Capacity      int     `json:"capacity"`
+	Policy        string  `json:"policy"`
+	Accesses      int     `json:"accesses"`
+	Hits          int     `json:"hits"`
+	Misses        int     `json:"misses"`
+	Evictions     int     `json:"evictions"`
+	HitRate       float64 `json:"hit_rate"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type StrconvBenchOutput struct {
+	Count         int     `json:"count"`
+	Type          string  `json:"type"`
+	Iterations    int     `json:"iterations"`
+	Sum           float64 `json:"sum"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerParse    float64 `json:"ns_per_parse"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type SyscallBenchOutput struct {
+	Iterations    int     `json:"iterations"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	NsPerSyscall  float64 `json:"ns_per_syscall"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type SortTypesOutput struct {
+	Count         int     `json:"count"`
+	Type          string  `json:"type"`
+	Seed          int64   `json:"seed"`
+	Stable        bool    `json:"stable"`
+	Sorted        bool    `json:"sorted"`
+	GenerateMs    float64 `json:"generate_ms"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type RWMutexBenchOutput struct {
+	Readers       int     `json:"readers"`
+	Writers       int     `json:"writers"`
+	Operations    int     `json:"operations"`
+	Reads         int64   `json:"reads"`
+	Writes        int64   `json:"writes"`
+	RWMutexMs     float64 `json:"rwmutex_ms"`
+	MutexMs       float64 `json:"mutex_ms"`
+	Correct       bool    `json:"correct"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type FixtureJSONOutput struct {
+	SizeKB        int                    `json:"size_kb"`
+	Seed          int64                  `json:"seed"`
+	Records       int                    `json:"records"`
+	Bytes         int                    `json:"bytes"`
+	Data          map[string]interface{} `json:"data"`
+	ServerType    string                 `json:"server_type"`
+	SchemaVersion string                 `json:"schema_version"`
+}
+
+type WorkerPoolBenchOutput struct {
+	Tasks         int     `json:"tasks"`
+	Workers       int     `json:"workers"`
+	WorkMs        int     `json:"work_ms"`
+	PoolMs        float64 `json:"pool_ms"`
+	PerTaskMs     float64 `json:"per_task_ms"`
+	Completed     int64   `json:"completed"`
+	Correct       bool    `json:"correct"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+type SimulateErrorOutput struct {
+	Code          string `json:"code"`
+	ServerType    string `json:"server_type"`
+	SchemaVersion string `json:"schema_version"`
+}
+
+type MemcopyBenchOutput struct {
+	SizeMB        int     `json:"size_mb"`
+	Iterations    int     `json:"iterations"`
+	BytesCopied   int64   `json:"bytes_copied"`
+	ElapsedMs     float64 `json:"elapsed_ms"`
+	BytesPerSec   float64 `json:"bytes_per_sec"`
+	GBPerSec      float64 `json:"gb_per_sec"`
+	ServerType    string  `json:"server_type"`
+	SchemaVersion string  `json:"schema_version"`
+}
+
+// Config holds the env-configured server settings, validated once at startup.
+// Each field's env tag names its variable, for /config. None of them holds
+// a secret, so /config shows every value as is.
+type Config struct {
+	Ports           []int                                 `env:"PORTS"`
+	FibMaxN         int                                   `env:"FIB_MAX_N"`
+	FetchTimeoutMs  int                                   `env:"FETCH_TIMEOUT_MS"`
+	EnableDebug     bool                                  `env:"ENABLE_DEBUG"`
+	EnableLeakTool  bool                                  `env:"ENABLE_LEAK_TOOL"`
+	Experimental    bool                                  `env:"EXPERIMENTAL"`
+	ShutdownGraceMs int                                   `env:"SHUTDOWN_GRACE_MS"`
+	RateLimitRPS    int                                   `env:"RATE_LIMIT_RPS"`
+	RateLimitBurst  int                                   `env:"RATE_LIMIT_BURST"`
+	RampSeconds     int                                   `env:"RAMP_SECONDS"`
+	MetricsStreamMs int                                   `env:"METRICS_STREAM_INTERVAL_MS"`
+	QueueCap        int                                   `env:"QUEUE_CAP"`
+	DisabledTools   map[string]bool                       `env:"DISABLED_TOOLS"`
+	EnableH2C       bool                                  `env:"ENABLE_H2C"`
+	WalkBaseDir     string                                `env:"WALK_BASE_DIR"`
+	MaxConns        int                                   `env:"MAX_CONNS"`
+	MinResponseMs   int                                   `env:"MIN_RESPONSE_MS"`
+	MemSoftLimitMB  int                                   `env:"MEM_SOFT_LIMIT_MB"`
+	IdempotencyTTL  int                                   `env:"IDEMPOTENCY_TTL_MS"`
+	RateLimitScope  string                                `env:"RATE_LIMIT_SCOPE"`
+	LogMalformed    bool                                  `env:"LOG_MALFORMED_FRAMES"`
+	FetchCacheTTL   int                                   `env:"FETCH_CACHE_TTL_MS"`
+	FetchCacheMax   int                                   `env:"FETCH_CACHE_MAX_ENTRIES"`
+	ChunkSize       int                                   `env:"CHUNK_SIZE"`
+	ChunkDelayMs    int                                   `env:"CHUNK_DELAY_MS"`
+	ReportSamples   int                                   `env:"REPORT_SAMPLES"`
+	LogSampleRate   float64                               `env:"LOG_SAMPLE_RATE"`
+	TCPNoDelay      bool                                  `env:"TCP_NODELAY"`
+	SOReusePort     bool                                  `env:"SO_REUSEPORT"`
+	ProcessMaxBytes int                                   `env:"PROCESS_MAX_BYTES"`
+	ProcessMaxKeys  int                                   `env:"PROCESS_MAX_KEYS"`
+	FetchMaxURLLen  int                                   `env:"FETCH_MAX_URL_LEN"`
+	StartupDelayMs  int                                   `env:"STARTUP_DELAY_MS"`
+	WriteTimeoutMs  int                                   `env:"WRITE_TIMEOUT_MS"`
+	CircuitFailures int                                   `env:"CIRCUIT_FAILURE_THRESHOLD"`
+	CircuitCooldown int                                   `env:"CIRCUIT_COOLDOWN_MS"`
+	FixedTimestamp  time.Time                             `env:"FIXED_TIMESTAMP"`
+	MaxGoroutines   int                                   `env:"MAX_GOROUTINES"`
+	ToolDefaults    map[string]map[string]json.RawMessage `env:"TOOL_DEFAULTS"`
+	MaxBodyBytes    int                                   `env:"MAX_BODY_BYTES"`
+	FetchGuard      string                                `env:"FETCH_GUARD"`
+}
+
+var cfg Config
+
+// envReader parses env vars and collects every invalid value, so a bad
+// deployment reports all of its problems at once instead of the first one.
+type envReader struct {
+	errs []string
+}
+
+func (r *envReader) int(name string, def, min, max int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < min || v > max {
+		r.errs = append(r.errs, fmt.Sprintf("%s=%q: deve ser um inteiro entre %d e %d", name, raw, min, max))
+		return def
+	}
+	return v
+}
+
+func (r *envReader) float(name string, def, min, max float64) float64 {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v < min || v > max {
+		r.errs = append(r.errs, fmt.Sprintf("%s=%q: deve ser um número entre %g e %g", name, raw, min, max))
+		return def
+	}
+	return v
+}
+
+func (r *envReader) bool(name string, def bool) bool {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("%s=%q: deve ser um booleano (true/false/1/0)", name, raw))
+		return def
+	}
+	return v
+}
+
+// intList parses a comma-separated list of integers, e.g. PORTS=8081,8091
+func (r *envReader) intList(name string, def []int, min, max int) []int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+	var values []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(raw, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v < min || v > max {
+			r.errs = append(r.errs, fmt.Sprintf("%s=%q: cada item deve ser um inteiro entre %d e %d", name, raw, min, max))
+			return def
+		}
+		if seen[v] {
+			r.errs = append(r.errs, fmt.Sprintf("%s=%q: valor duplicado %d", name, raw, v))
+			return def
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	return values
+}
+
+// dir reads an absolute path to an existing directory, resolving symlinks
+func (r *envReader) dir(name, def string) string {
+	raw := os.Getenv(name)
+	if raw == "" {
+		raw = def
+	}
+	resolved, err := filepath.EvalSymlinks(raw)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = os.Stat(resolved); err == nil && !info.IsDir() {
+			err = fmt.Errorf("não é um diretório")
+		}
+	}
+	if err != nil || !filepath.IsAbs(raw) {
+		r.errs = append(r.errs, fmt.Sprintf("%s=%q: deve ser um diretório absoluto existente", name, raw))
+		return raw
+	}
+	return resolved
+}
+
+// timestamp parses an optional RFC 3339 instant; unset means the zero time
+func (r *envReader) timestamp(name string) time.Time {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("%s=%q: deve ser um instante RFC 3339, ex. 2024-01-01T00:00:00Z", name, raw))
+	}
+	return t
+}
+
+// toolDefaults parses a JSON object mapping tool names to argument objects,
+// e.g. TOOL_DEFAULTS={"simulate_database_query":{"delay_ms":50}}
+func (r *envReader) toolDefaults(name string) map[string]map[string]json.RawMessage {
+	defaults := make(map[string]map[string]json.RawMessage)
+	raw := os.Getenv(name)
+	if raw == "" {
+		return defaults
+	}
+	if err := json.Unmarshal([]byte(raw), &defaults); err != nil {
+		r.errs = append(r.errs, fmt.Sprintf("%s: deve ser um objeto JSON de ferramenta para objeto de argumentos: %v", name, err))
+	}
+	return defaults
+}
+
+// set parses a comma-separated list of names, e.g. DISABLED_TOOLS=a,b
+func (r *envReader) set(name string) map[string]bool {
+	values := make(map[string]bool)
+	for _, part := range strings.Split(os.Getenv(name), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values[part] = true
+		}
+	}
+	return values
+}
+
+// choice reads a value that must be one of allowed
+func (r *envReader) choice(name, def string, allowed ...string) string {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+	if !slices.Contains(allowed, raw) {
+		r.errs = append(r.errs, fmt.Sprintf("%s=%q: deve ser um de %s", name, raw, strings.Join(allowed, ", ")))
+		return def
+	}
+	return raw
+}
+
+func loadConfig() (Config, error) {
+	r := &envReader{}
+	port := r.int("PORT", 8081, 1, 65535)
+	c := Config{
+		// PORTS (comma-separated) starts one server instance per port and overrides PORT
+		Ports:           r.intList("PORTS", []int{port}, 1, 65535),
+		FibMaxN:         r.int("FIB_MAX_N", 40, 0, 45),
+		FetchTimeoutMs:  r.int("FETCH_TIMEOUT_MS", 10000, 1, 120000),
+		EnableDebug:     r.bool("ENABLE_DEBUG", false),
+		EnableLeakTool:  r.bool("ENABLE_LEAK_TOOL", false),
+		Experimental:    r.bool("EXPERIMENTAL", false),
+		ShutdownGraceMs: r.int("SHUTDOWN_GRACE_MS", 10000, 0, 300000),
+		RateLimitRPS:    r.int("RATE_LIMIT_RPS", 0, 0, 1000000),
+		RateLimitBurst:  r.int("RATE_LIMIT_BURST", 0, 0, 1000000),
+		RampSeconds:     r.int("RAMP_SECONDS", 0, 0, 3600),
+		MetricsStreamMs: r.int("METRICS_STREAM_INTERVAL_MS", 1000, 50, 60000),
+		QueueCap:        r.int("QUEUE_CAP", 1000, 1, 1000000),
+		DisabledTools:   r.set("DISABLED_TOOLS"),
+		EnableH2C:       r.bool("ENABLE_H2C", false),
+		WalkBaseDir:     r.dir("WALK_BASE_DIR", "/usr"),
+		MaxConns:        r.int("MAX_CONNS", 0, 0, 1000000),
+		MinResponseMs:   r.int("MIN_RESPONSE_MS", 0, 0, 60000),
+		MemSoftLimitMB:  r.int("MEM_SOFT_LIMIT_MB", 0, 0, 1048576),
+		IdempotencyTTL:  r.int("IDEMPOTENCY_TTL_MS", 60000, 1000, 3600000),
+		RateLimitScope:  r.choice("RATE_LIMIT_SCOPE", "global", "global", "client"),
+		LogMalformed:    r.bool("LOG_MALFORMED_FRAMES", true),
+		FetchCacheTTL:   r.int("FETCH_CACHE_TTL_MS", 30000, 0, 86400000),
+		FetchCacheMax:   r.int("FETCH_CACHE_MAX_ENTRIES", 1000, 1, 1000000),
+		ChunkSize:       r.int("CHUNK_SIZE", 0, 0, 1048576),
+		ChunkDelayMs:    r.int("CHUNK_DELAY_MS", 0, 0, 10000),
+		ReportSamples:   r.int("REPORT_SAMPLES", 0, 0, 1000000),
+		LogSampleRate:   r.float("LOG_SAMPLE_RATE", 0, 0, 1),
+		TCPNoDelay:      r.bool("TCP_NODELAY", true),
+		SOReusePort:     r.bool("SO_REUSEPORT", false),
+		ProcessMaxBytes: r.int("PROCESS_MAX_BYTES", 1<<20, 1, 1<<30),
+		ProcessMaxKeys:  r.int("PROCESS_MAX_KEYS", 100000, 1, 100000000),
+		FetchMaxURLLen:  r.int("FETCH_MAX_URL_LEN", 2048, 1, 1000000),
+		StartupDelayMs:  r.int("STARTUP_DELAY_MS", 0, 0, 600000),
+		WriteTimeoutMs:  r.int("WRITE_TIMEOUT_MS", 0, 0, 600000),
+		// CIRCUIT_FAILURE_THRESHOLD=0 disables the fetch circuit breaker
+		CircuitFailures: r.int("CIRCUIT_FAILURE_THRESHOLD", 5, 0, 1000000),
+		CircuitCooldown: r.int("CIRCUIT_COOLDOWN_MS", 30000, 1, 3600000),
+		FixedTimestamp:  r.timestamp("FIXED_TIMESTAMP"),
+		MaxGoroutines:   r.int("MAX_GOROUTINES", 0, 0, 10000000),
+		// TOOL_DEFAULTS is a JSON object of tool name -> default arguments
+		ToolDefaults: r.toolDefaults("TOOL_DEFAULTS"),
+		MaxBodyBytes: r.int("MAX_BODY_BYTES", 64<<20, 1, 1<<30),
+		FetchGuard:   r.choice("FETCH_GUARD", "standard", "standard", "strict", "off"),
+	}
+	if c.RampSeconds > 0 && c.RateLimitRPS == 0 {
+		r.errs = append(r.errs, "RAMP_SECONDS requer RATE_LIMIT_RPS > 0")
+	}
+	if c.ChunkDelayMs > 0 && c.ChunkSize == 0 {
+		r.errs = append(r.errs, "CHUNK_DELAY_MS requer CHUNK_SIZE > 0")
+	}
+	if c.SOReusePort && !reusePortSupported {
+		r.errs = append(r.errs, "SO_REUSEPORT não é suportado em "+runtime.GOOS)
+	}
+	if c.RateLimitBurst == 0 {
+		c.RateLimitBurst = max(c.RateLimitRPS, 1)
+	}
+	if len(r.errs) > 0 {
+		return c, fmt.Errorf("configuração inválida:\n  %s", strings.Join(r.errs, "\n  "))
+	}
+	return c, nil
+}
+
+// HTTP client with timeout for external requests (timeout set from config in main)
+var httpClient = &http.Client{Timeout: 10 * time.Second, Transport: newFetchTransport()}
+
+// newFetchTransport is the default transport with FETCH_GUARD checked on
+// every dial
+func newFetchTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   fetchDialControl,
+	}).DialContext
+	return t
+}
+
+// errFetchBlocked marks a fetch refused by FETCH_GUARD
+var errFetchBlocked = errors.New("endereço bloqueado por FETCH_GUARD")
+
+// fetchAddressAllowed applies FETCH_GUARD to a resolved address. "standard"
+// refuses loopback, link-local (including the 169.254.169.254 metadata
+// endpoint), unspecified and multicast addresses; "strict" also refuses
+// private ranges, which rules out a mock API on a Docker network.
+func fetchAddressAllowed(ip net.IP) bool {
+	switch cfg.FetchGuard {
+	case "off":
+		return true
+	case "strict":
+		if ip.IsPrivate() {
+			return false
+		}
+	}
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsUnspecified() && !ip.IsMulticast()
+}
+
+// fetchDialControl sees the address after DNS resolution and on every
+// redirect hop, so neither a rebinding hostname nor a redirect gets past it
+func fetchDialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !fetchAddressAllowed(ip) {
+		return fmt.Errorf("%w: %s", errFetchBlocked, host)
+	}
+	return nil
+}
+
+// Process-global cache shared by all sessions for cache_op
+var sharedCache sync.Map
+
+// Memory deliberately retained by leak_memory; never freed
+var (
+	leakMu     sync.Mutex
+	leakChunks [][]byte
+	leakTotal  int64
+)
+
+// Bounded producer/consumer queue for enqueue/dequeue (created in main with QUEUE_CAP)
+var workQueue chan string
+
+// shutdownHook is implemented by stateful tools that hold something worth
+// releasing once the HTTP servers have drained
+type shutdownHook interface {
+	Shutdown(ctx context.Context) error
+}
+
+// shutdownHookFunc adapts a plain function to shutdownHook
+type shutdownHookFunc func(ctx context.Context) error
+
+func (f shutdownHookFunc) Shutdown(ctx context.Context) error { return f(ctx) }
+
+type namedShutdownHook struct {
+	name string
+	hook shutdownHook
+}
+
+// shutdownHooks run in registration order; written only during startup
+var shutdownHooks []namedShutdownHook
+
+func registerShutdownHook(name string, hook shutdownHook) {
+	shutdownHooks = append(shutdownHooks, namedShutdownHook{name, hook})
+}
+
+// shutdownHookTimeout bounds the hooks as a whole, independently of the
+// drain's grace period
+const shutdownHookTimeout = 5 * time.Second
+
+// runShutdownHooks calls every hook in turn; a failing hook is logged and
+// doesn't stop the rest
+func runShutdownHooks(ctx context.Context) {
+	for _, h := range shutdownHooks {
+		if err := h.hook.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "shutdown hook %s: %v\n", h.name, err)
+		}
+	}
+}
+
+// shutdownSharedCache empties the cache_op store
+func shutdownSharedCache(ctx context.Context) error {
+	n := 0
+	sharedCache.Range(func(key, _ any) bool {
+		sharedCache.Delete(key)
+		n++
+		return true
+	})
+	fmt.Printf("Cache: cleared %d entries\n", n)
+	return nil
+}
+
+// shutdownWorkQueue drains whatever was enqueued but never dequeued
+func shutdownWorkQueue(ctx context.Context) error {
+	n := 0
+	for {
+		select {
+		case <-workQueue:
+			n++
+		default:
+			fmt.Printf("Queue: discarded %d pending items\n", n)
+			return nil
+		}
+	}
+}
+
+// elapsedMs returns the time since start in fractional milliseconds, for
+// micro-benchmarks where whole milliseconds would round to zero.
+func elapsedMs(start time.Time) float64 {
+	return float64(time.Since(start).Nanoseconds()) / 1e6
+}
+
+// Tool handlers
+func handleFibonacci(ctx context.Context, req *mcp.CallToolRequest, args FibonacciArgs) (*mcp.CallToolResult, FibonacciOutput, error) {
+	if args.N < 0 || args.N > cfg.FibMaxN {
+		return nil, FibonacciOutput{}, fmt.Errorf("n deve estar entre 0 e %d", cfg.FibMaxN)
+	}
+
+	var fib func(int) int
+	fib = func(x int) int {
+		if x <= 1 {
+			return x
+		}
+		return fib(x-1) + fib(x-2)
+	}
+
+	return nil, FibonacciOutput{
+		Input:         args.N,
+		Result:        fib(args.N),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// fetchCacheEntry is a cached fetch_external_data result
+type fetchCacheEntry struct {
+	statusCode int
+	redirects  int
+	stored     time.Time
+	expires    time.Time
+}
+
+// fetchCache holds fetch results keyed by method+URL, bounded by
+// FETCH_CACHE_MAX_ENTRIES with the oldest entry evicted first
+var fetchCache = struct {
+	mu      sync.Mutex
+	entries map[string]fetchCacheEntry
+}{entries: make(map[string]fetchCacheEntry)}
+
+// fetchCacheTTL picks how long a response may be cached: Cache-Control
+// max-age when present, FETCH_CACHE_TTL_MS otherwise. Zero means don't cache.
+// Every directive is read first, so no-store or no-cache wins wherever it
+// appears, across repeated Cache-Control headers too.
+func fetchCacheTTL(resp *http.Response) time.Duration {
+	ttl := time.Duration(cfg.FetchCacheTTL) * time.Millisecond
+	maxAgeSeen := false
+	for _, header := range resp.Header.Values("Cache-Control") {
+		for _, directive := range strings.Split(header, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			name, value, _ := strings.Cut(directive, "=")
+			switch name {
+			case "no-store", "no-cache":
+				return 0
+			case "max-age":
+				// The first valid max-age counts, as RFC 9111 asks of duplicates
+				if secs, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && secs >= 0 && !maxAgeSeen {
+					ttl, maxAgeSeen = time.Duration(secs)*time.Second, true
+				}
+			}
+		}
+	}
+	return ttl
+}
+
+func fetchCacheGet(key string) (fetchCacheEntry, bool) {
+	fetchCache.mu.Lock()
+	defer fetchCache.mu.Unlock()
+	e, ok := fetchCache.entries[key]
+	if ok && time.Now().After(e.expires) {
+		delete(fetchCache.entries, key)
+		return fetchCacheEntry{}, false
+	}
+	return e, ok
+}
+
+func fetchCachePut(key string, statusCode, redirects int, ttl time.Duration) {
+	now := time.Now()
+	fetchCache.mu.Lock()
+	defer fetchCache.mu.Unlock()
+	if _, exists := fetchCache.entries[key]; !exists && len(fetchCache.entries) >= cfg.FetchCacheMax {
+		// Linear scan is fine at the configured sizes and keeps eviction exact
+		oldestKey, oldest := "", now
+		for k, e := range fetchCache.entries {
+			if !e.stored.After(oldest) {
+				oldestKey, oldest = k, e.stored
+			}
+		}
+		delete(fetchCache.entries, oldestKey)
+	}
+	fetchCache.entries[key] = fetchCacheEntry{statusCode: statusCode, redirects: redirects, stored: now, expires: now.Add(ttl)}
+}
+
+// fetchCircuits tracks consecutive fetch failures per host. Once a host
+// reaches CIRCUIT_FAILURE_THRESHOLD the circuit opens and fetches to it fail
+// fast until CIRCUIT_COOLDOWN_MS passes; the next fetch after that is a trial
+// whose failure reopens the circuit immediately.
+var fetchCircuits = struct {
+	mu    sync.Mutex
+	hosts map[string]*circuitState
+}{hosts: make(map[string]*circuitState)}
+
+type circuitState struct {
+	failures  int
+	openUntil time.Time
+}
+
+// circuitAllow reports whether a fetch to host may proceed
+func circuitAllow(host string) bool {
+	if cfg.CircuitFailures == 0 {
+		return true
+	}
+	fetchCircuits.mu.Lock()
+	defer fetchCircuits.mu.Unlock()
+	c, ok := fetchCircuits.hosts[host]
+	return !ok || !time.Now().Before(c.openUntil)
+}
+
+// circuitRecord updates host's state after a fetch; 5xx counts as a failure
+func circuitRecord(host string, failed bool) {
+	if cfg.CircuitFailures == 0 {
+		return
+	}
+	fetchCircuits.mu.Lock()
+	defer fetchCircuits.mu.Unlock()
+	if !failed {
+		delete(fetchCircuits.hosts, host)
+		return
+	}
+	c, ok := fetchCircuits.hosts[host]
+	if !ok {
+		c = &circuitState{}
+		fetchCircuits.hosts[host] = c
+	}
+	c.failures++
+	if c.failures >= cfg.CircuitFailures {
+		c.openUntil = time.Now().Add(time.Duration(cfg.CircuitCooldown) * time.Millisecond)
+	}
+}
+
+// validateFetchURL rejects endpoints that aren't absolute http(s) URLs, which
+// http.Get would otherwise fail on with a less obvious message
+func validateFetchURL(endpoint string) error {
+	if endpoint == "" {
+		return fmt.Errorf("endpoint vazio")
+	}
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return fmt.Errorf("URL inválida: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL inválida: esquema deve ser http ou https, recebido %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL inválida: host ausente")
+	}
+	return nil
+}
+
+// defaultMaxRedirects matches net/http's own limit
+const defaultMaxRedirects = 10
+
+// fetchRedirects counts one fetch's redirects against its limit
+type fetchRedirects struct {
+	count    int
+	exceeded bool
+}
+
+// redirectLimitedClient returns a shallow copy of httpClient that shares its
+// transport and timeout but counts redirects into r for this request alone.
+// Not following returns the 3xx itself; going past max is an error.
+func redirectLimitedClient(follow bool, max int, r *fetchRedirects) *http.Client {
+	client := *httpClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !follow {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > max {
+			r.exceeded = true
+			return fmt.Errorf("mais de %d redirecionamentos", max)
+		}
+		r.count = len(via)
+		return nil
+	}
+	return &client
+}
+
+func handleFetchData(ctx context.Context, req *mcp.CallToolRequest, args FetchDataArgs) (*mcp.CallToolResult, FetchDataOutput, error) {
+	if n := len(args.Endpoint); n > cfg.FetchMaxURLLen {
+		return nil, FetchDataOutput{}, inputLimitError("endpoint", "FETCH_MAX_URL_LEN", cfg.FetchMaxURLLen, n)
+	}
+	if err := validateFetchURL(args.Endpoint); err != nil {
+		return nil, FetchDataOutput{
+			URL:           args.Endpoint,
+			Error:         err.Error(),
+			ErrorKind:     "invalid_url",
+			ServerType:    "go",
+			SchemaVersion: outputSchemaVersion,
+		}, nil
+	}
+	if args.MaxRedirects < 0 || args.MaxRedirects > 50 {
+		return nil, FetchDataOutput{}, fmt.Errorf("max_redirects deve estar entre 0 e 50")
+	}
+	maxRedirects := defaultMaxRedirects
+	if args.MaxRedirects > 0 {
+		maxRedirects = args.MaxRedirects
+	}
+	if args.FollowRedirects != nil && !*args.FollowRedirects {
+		maxRedirects = 0
+	}
+	startTime := time.Now()
+
+	// The redirect limit is part of the key: with a lower one the same URL
+	// can end on a different response
+	cacheKey := fmt.Sprintf("%s %s redirects=%d", http.MethodGet, args.Endpoint, maxRedirects)
+	if args.UseCache {
+		if e, ok := fetchCacheGet(cacheKey); ok {
+			return nil, FetchDataOutput{
+				URL:            args.Endpoint,
+				StatusCode:     e.statusCode,
+				ResponseTimeMs: time.Since(startTime).Milliseconds(),
+				CacheHit:       true,
+				Redirects:      e.redirects,
+				ServerType:     "go",
+				SchemaVersion:  outputSchemaVersion,
+			}, nil
+		}
+	}
+
+	// validateFetchURL already accepted the URL, so this parse can't fail
+	u, _ := url.Parse(args.Endpoint)
+	if !circuitAllow(u.Host) {
+		return nil, FetchDataOutput{
+			URL:            args.Endpoint,
+			ResponseTimeMs: time.Since(startTime).Milliseconds(),
+			CircuitOpen:    true,
+			Error:          fmt.Sprintf("circuito aberto para %s", u.Host),
+			ErrorKind:      "circuit_open",
+			ServerType:     "go",
+			SchemaVersion:  outputSchemaVersion,
+		}, nil
+	}
+
+	var rd fetchRedirects
+	client := redirectLimitedClient(args.FollowRedirects == nil || *args.FollowRedirects, maxRedirects, &rd)
+	resp, err := client.Get(args.Endpoint)
+	responseTimeMs := time.Since(startTime).Milliseconds()
+	redirects, tooManyRedirects := rd.count, rd.exceeded
+	// Too many redirects is the caller's limit and a blocked address is
+	// FETCH_GUARD's, neither is the host failing
+	blocked := errors.Is(err, errFetchBlocked)
+	if !tooManyRedirects && !blocked {
+		circuitRecord(u.Host, err != nil || resp.StatusCode >= 500)
+	}
+
+	if err != nil {
+		out := FetchDataOutput{
+			URL:            args.Endpoint,
+			StatusCode:     0,
+			ResponseTimeMs: responseTimeMs,
+			Redirects:      redirects,
+			Error:          err.Error(),
+			ServerType:     "go",
+			SchemaVersion:  outputSchemaVersion,
+		}
+		switch {
+		case tooManyRedirects:
+			out.ErrorKind = "too_many_redirects"
+		case blocked:
+			out.ErrorKind = "blocked_address"
+		}
+		return nil, out, nil
+	}
+	defer resp.Body.Close()
+
+	if args.UseCache {
+		if ttl := fetchCacheTTL(resp); ttl > 0 {
+			fetchCachePut(cacheKey, resp.StatusCode, redirects, ttl)
+		}
+	}
+
+	return nil, FetchDataOutput{
+		URL:            args.Endpoint,
+		StatusCode:     resp.StatusCode,
+		ResponseTimeMs: responseTimeMs,
+		Redirects:      redirects,
+		ServerType:     "go",
+		SchemaVersion:  outputSchemaVersion,
+	}, nil
+}
+
+// transformCancelCheckEvery is how many nodes transformStrings visits between
+// context checks, keeping the check out of the per-node cost
+const transformCancelCheckEvery = 1024
+
+func handleProcessData(ctx context.Context, req *mcp.CallToolRequest, args ProcessDataArgs) (*mcp.CallToolResult, ProcessDataOutput, error) {
+	// Once the context is done every remaining node returns immediately, so
+	// the recursion unwinds without finishing the transform
+	nodes := 0
+	cancelled := false
+	var transformStrings func(interface{}) interface{}
+	transformStrings = func(obj interface{}) interface{} {
+		if cancelled {
+			return nil
+		}
+		if nodes%transformCancelCheckEvery == 0 && ctx.Err() != nil {
+			cancelled = true
+			return nil
+		}
+		nodes++
+		switch v := obj.(type) {
+		case map[string]interface{}:
+			result := make(map[string]interface{})
+			for key, val := range v {
+				result[key] = transformStrings(val)
+			}
+			return result
+		case []interface{}:
+			result := make([]interface{}, len(v))
+			for i, val := range v {
+				result[i] = transformStrings(val)
+			}
+			return result
+		case string:
+			return strings.ToUpper(v)
+		case json.Number:
+			if args.PreciseNumbers {
+				return v
+			}
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+			return v
+		default:
+			return v
+		}
+	}
+
+	transformed, _ := transformStrings(args.Data).(map[string]interface{})
+	if cancelled {
+		return nil, ProcessDataOutput{
+			OriginalKeys:    []string{},
+			TransformedData: map[string]interface{}{},
+			NodesProcessed:  nodes,
+			Cancelled:       true,
+			ServerType:      "go",
+			SchemaVersion:   outputSchemaVersion,
+		}, nil
+	}
+	originalKeys := make([]string, 0, len(args.Data))
+	for k := range args.Data {
+		originalKeys = append(originalKeys, k)
+	}
+
+	return nil, ProcessDataOutput{
+		OriginalKeys:    originalKeys,
+		TransformedData: transformed,
+		NodesProcessed:  nodes,
+		ServerType:      "go",
+		SchemaVersion:   outputSchemaVersion,
+	}, nil
+}
+
+// inputLimitError reports an argument over its configured size limit as
+// invalid params, with the field, limit and actual size as structured data
+func inputLimitError(field, envVar string, limit, actual int) error {
+	data, _ := json.Marshal(map[string]interface{}{
+		"field":       field,
+		"limit":       limit,
+		"actual":      actual,
+		"env_var":     envVar,
+		"server_type": "go",
+	})
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: fmt.Sprintf("%s excede o limite de %d (%d)", field, limit, actual),
+		Data:    data,
+	}
+}
+
+// countKeys counts object keys at every nesting level of v. It is linear in
+// the payload, which PROCESS_MAX_BYTES has already bounded.
+func countKeys(v interface{}) int {
+	count := 0
+	switch t := v.(type) {
+	case map[string]interface{}:
+		count += len(t)
+		for _, child := range t {
+			count += countKeys(child)
+		}
+	case []interface{}:
+		for _, child := range t {
+			count += countKeys(child)
+		}
+	}
+	return count
+}
+
+// handleProcessDataRaw decodes the arguments itself with UseNumber. The typed
+// mcp.AddTool path validates arguments through map[string]any, which turns every
+// number into float64 before handleProcessData ever sees it.
+func handleProcessDataRaw(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if n := len(req.Params.Arguments); n > cfg.ProcessMaxBytes {
+		return nil, inputLimitError("arguments", "PROCESS_MAX_BYTES", cfg.ProcessMaxBytes, n)
+	}
+	var args ProcessDataArgs
+	dec := json.NewDecoder(bytes.NewReader(req.Params.Arguments))
+	dec.UseNumber()
+	if err := dec.Decode(&args); err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf("argumentos inválidos: %v", err)}
+	}
+	if args.Data == nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "data é obrigatório"}
+	}
+	if n := countKeys(args.Data); n > cfg.ProcessMaxKeys {
+		return nil, inputLimitError("data", "PROCESS_MAX_KEYS", cfg.ProcessMaxKeys, n)
+	}
+
+	_, out, err := handleProcessData(ctx, req, args)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+	outJSON, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling output: %w", err)
+	}
+	// A cancelled transform is a failure, but still reports how far it got
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(outJSON)}},
+		StructuredContent: json.RawMessage(outJSON),
+		IsError:           out.Cancelled,
+	}, nil
+}
+
+// withToolDefaults merges TOOL_DEFAULTS for tool under the client's
+// arguments: a field the client sent always wins. Arguments that aren't a
+// JSON object are returned as is for the tool's own validation to reject.
+func withToolDefaults(tool string, raw json.RawMessage) json.RawMessage {
+	defaults := cfg.ToolDefaults[tool]
+	if len(defaults) == 0 {
+		return raw
+	}
+	var args map[string]json.RawMessage
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return raw
+		}
+	}
+	if args == nil {
+		args = make(map[string]json.RawMessage, len(defaults))
+	}
+	for k, v := range defaults {
+		if _, ok := args[k]; !ok {
+			args[k] = v
+		}
+	}
+	merged, err := json.Marshal(args)
+	if err != nil {
+		return raw
+	}
+	return merged
+}
+
+// toolDefaultsMiddleware applies TOOL_DEFAULTS before the SDK validates the
+// arguments, so defaulted fields satisfy required ones
+func toolDefaultsMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "tools/call" {
+			if p, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok && p != nil {
+				p.Arguments = withToolDefaults(p.Name, p.Arguments)
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// mustSchema derives a JSON schema from T, for tools registered with a raw handler
+func mustSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// latencyProfiles map a base delay to one sampled delay. Every sample is
+// jittered by ±10%; the profiles differ in how often they leave the base.
+var latencyProfiles = map[string]func(base float64) float64{
+	"steady": func(base float64) float64 {
+		return base
+	},
+	// 30% of queries take 5x the base, giving two clear latency modes
+	"bimodal": func(base float64) float64 {
+		if rand.Float64() < 0.3 {
+			return base * 5
+		}
+		return base
+	},
+	// 1% of queries take 20x the base, so only p99 and above move
+	"p99-spike": func(base float64) float64 {
+		if rand.Float64() < 0.01 {
+			return base * 20
+		}
+		return base
+	},
+}
+
+// maxSampledDelay caps a latency profile sample; a p99 spike on the largest
+// delay_ms would otherwise hold the call for nearly two minutes
+const maxSampledDelay = 30 * time.Second
+
+// responseTimestamp is the time tool outputs report: FIXED_TIMESTAMP when
+// set, so payloads can be checksummed across runs, and the wall clock otherwise
+func responseTimestamp() time.Time {
+	if !cfg.FixedTimestamp.IsZero() {
+		return cfg.FixedTimestamp
+	}
+	return time.Now()
+}
+
+func handleDatabaseQuery(ctx context.Context, req *mcp.CallToolRequest, args DatabaseQueryArgs) (*mcp.CallToolResult, DatabaseOutput, error) {
+	if args.DelayMs < 0 || args.DelayMs > 5000 {
+		return nil, DatabaseOutput{}, fmt.Errorf("delay_ms deve estar entre 0 e 5000")
+	}
+	if args.RowCount < 0 || args.RowCount > 10000 {
+		return nil, DatabaseOutput{}, fmt.Errorf("row_count deve estar entre 0 e 10000")
+	}
+
+	delay := time.Duration(args.DelayMs) * time.Millisecond
+	if args.LatencyProfile != "" {
+		sample, ok := latencyProfiles[args.LatencyProfile]
+		if !ok {
+			return nil, DatabaseOutput{}, fmt.Errorf("latency_profile deve ser steady, bimodal ou p99-spike")
+		}
+		base := float64(args.DelayMs)
+		if base == 0 {
+			base = 10
+		}
+		ms := sample(base) * (0.9 + 0.2*rand.Float64())
+		delay = min(time.Duration(ms*float64(time.Millisecond)), maxSampledDelay)
+	}
+	startTime := time.Now()
+	timer := time.NewTimer(delay)
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		timer.Stop()
+		return nil, DatabaseOutput{}, ctx.Err()
+	}
+	realizedMs := elapsedMs(startTime)
+	// A measured delay differs on every run; leave it out when the output is
+	// meant to be reproducible
+	if !cfg.FixedTimestamp.IsZero() {
+		realizedMs = 0
+	}
+
+	// Synthetic result set, sized independently of the delay
+	var rows []map[string]interface{}
+	if args.RowCount > 0 {
+		rows = make([]map[string]interface{}, args.RowCount)
+		for i := range rows {
+			rows[i] = map[string]interface{}{
+				"id":     i + 1,
+				"name":   fmt.Sprintf("row-%d", i+1),
+				"value":  float64(i) * 1.5,
+				"active": i%2 == 0,
+			}
+		}
+	}
+
+	return nil, DatabaseOutput{
+		Query:         args.Query,
+		DelayMs:       args.DelayMs,
+		Profile:       args.LatencyProfile,
+		RealizedMs:    realizedMs,
+		Timestamp:     responseTimestamp().UTC().Format(time.RFC3339),
+		RowCount:      args.RowCount,
+		Rows:          rows,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleRegexCompile(ctx context.Context, req *mcp.CallToolRequest, args RegexCompileArgs) (*mcp.CallToolResult, RegexCompileOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 100000 {
+		return nil, RegexCompileOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000")
+	}
+	if _, err := regexp.Compile(args.Pattern); err != nil {
+		return nil, RegexCompileOutput{}, fmt.Errorf("padrão inválido: %v", err)
+	}
+
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		regexp.MustCompile(args.Pattern)
+	}
+	total := time.Since(startTime)
+
+	return nil, RegexCompileOutput{
+		Pattern:       args.Pattern,
+		Iterations:    args.Iterations,
+		TotalTimeMs:   float64(total.Nanoseconds()) / 1e6,
+		PerCompileNs:  float64(total.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleCacheOp(ctx context.Context, req *mcp.CallToolRequest, args CacheOpArgs) (*mcp.CallToolResult, CacheOpOutput, error) {
+	if args.Key == "" {
+		return nil, CacheOpOutput{}, fmt.Errorf("key não pode ser vazia")
+	}
+
+	out := CacheOpOutput{Op: args.Op, Key: args.Key, ServerType: "go", SchemaVersion: outputSchemaVersion}
+	startTime := time.Now()
+	switch args.Op {
+	case "get":
+		if v, ok := sharedCache.Load(args.Key); ok {
+			out.Value = v.(string)
+			out.Found = true
+		}
+	case "set":
+		_, out.Found = sharedCache.Swap(args.Key, args.Value)
+		out.Value = args.Value
+	case "delete":
+		_, out.Found = sharedCache.LoadAndDelete(args.Key)
+	default:
+		return nil, CacheOpOutput{}, fmt.Errorf("op deve ser get, set ou delete")
+	}
+	out.LatencyNs = time.Since(startTime).Nanoseconds()
+
+	return nil, out, nil
+}
+
+// parseJSONPath splits a path like "$.users[0].name" or "a['b c']" into
+// segments: string keys and int indexes.
+func parseJSONPath(path string) ([]interface{}, error) {
+	var segments []interface{}
+	p := strings.TrimPrefix(path, "$")
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("chave vazia no caminho %q", path)
+			}
+			segments = append(segments, p[:end])
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("colchete não fechado no caminho %q", path)
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, inner[1:len(inner)-1])
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("índice inválido %q no caminho %q", inner, path)
+			}
+			segments = append(segments, idx)
+		default:
+			// Allow a bare leading key ("a.b" as well as ".a.b")
+			p = "." + p
+		}
+	}
+	return segments, nil
+}
+
+func handleJSONQuery(ctx context.Context, req *mcp.CallToolRequest, args JSONQueryArgs) (*mcp.CallToolResult, JSONQueryOutput, error) {
+	startTime := time.Now()
+
+	segments, err := parseJSONPath(args.Path)
+	if err != nil {
+		return nil, JSONQueryOutput{}, err
+	}
+
+	var current interface{} = args.Data
+	for i, seg := range segments {
+		switch key := seg.(type) {
+		case string:
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, JSONQueryOutput{}, fmt.Errorf("caminho não encontrado: segmento %d (%q) não é um objeto", i, key)
+			}
+			if current, ok = obj[key]; !ok {
+				return nil, JSONQueryOutput{}, fmt.Errorf("caminho não encontrado: chave %q não existe", key)
+			}
+		case int:
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, JSONQueryOutput{}, fmt.Errorf("caminho não encontrado: segmento %d ([%d]) não é um array", i, key)
+			}
+			if key >= len(arr) {
+				return nil, JSONQueryOutput{}, fmt.Errorf("caminho não encontrado: índice %d fora do array de tamanho %d", key, len(arr))
+			}
+			current = arr[key]
+		}
+	}
+
+	return nil, JSONQueryOutput{
+		Path:          args.Path,
+		Value:         current,
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// handleDebugGC forces a collection and reports how much heap it reclaimed,
+// so benchmark phases can start from a clean heap.
+func handleDebugGC(w http.ResponseWriter, r *http.Request) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	startTime := time.Now()
+	runtime.GC()
+	durationMs := elapsedMs(startTime)
+	runtime.ReadMemStats(&after)
+
+	reclaimed := int64(before.HeapAlloc) - int64(after.HeapAlloc)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"heap_alloc_before": before.HeapAlloc,
+		"heap_alloc_after":  after.HeapAlloc,
+		"reclaimed_bytes":   reclaimed,
+		"pause_ns":          after.PauseNs[(after.NumGC+255)%256],
+		"num_gc":            after.NumGC,
+		"duration_ms":       durationMs,
+		"server_type":       "go",
+	})
+}
+
+// handleDebugGOGC sets the GC target percentage at runtime (-1 disables GC),
+// so GC settings can be compared across benchmark phases without a restart.
+func handleDebugGOGC(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("percent")
+	percent, err := strconv.Atoi(raw)
+	if err != nil || percent < -1 || percent > 1000000 {
+		http.Error(w, "percent deve ser um inteiro entre -1 e 1000000", http.StatusBadRequest)
+		return
+	}
+	previous := debug.SetGCPercent(percent)
+	fmt.Printf("GOGC changed: %d -> %d\n", previous, percent)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"previous":    previous,
+		"current":     percent,
+		"server_type": "go",
+	})
+}
+
+func handleCollatz(ctx context.Context, req *mcp.CallToolRequest, args CollatzArgs) (*mcp.CallToolResult, CollatzOutput, error) {
+	if args.Start < 1 {
+		return nil, CollatzOutput{}, fmt.Errorf("start deve ser maior ou igual a 1")
+	}
+
+	startTime := time.Now()
+	x, maxValue, steps := args.Start, args.Start, 0
+	for x != 1 {
+		if x%2 == 0 {
+			x /= 2
+		} else {
+			// 3x+1 would overflow int64
+			if x > (math.MaxInt64-1)/3 {
+				return nil, CollatzOutput{}, fmt.Errorf("overflow de int64 no passo %d (valor %d)", steps, x)
+			}
+			x = 3*x + 1
+		}
+		if x > maxValue {
+			maxValue = x
+		}
+		steps++
+	}
+
+	return nil, CollatzOutput{
+		Start:         args.Start,
+		Steps:         steps,
+		MaxValue:      maxValue,
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleLeakMemory(ctx context.Context, req *mcp.CallToolRequest, args LeakMemoryArgs) (*mcp.CallToolResult, LeakMemoryOutput, error) {
+	if args.KB < 1 || args.KB > 102400 {
+		return nil, LeakMemoryOutput{}, fmt.Errorf("kb deve estar entre 1 e 102400")
+	}
+
+	chunk := make([]byte, args.KB*1024)
+	// Touch every page so the memory is actually resident, not just reserved
+	for i := 0; i < len(chunk); i += 4096 {
+		chunk[i] = 1
+	}
+
+	leakMu.Lock()
+	leakChunks = append(leakChunks, chunk)
+	leakTotal += int64(args.KB)
+	total, chunks := leakTotal, len(leakChunks)
+	leakMu.Unlock()
+
+	return nil, LeakMemoryOutput{
+		AddedKB:         args.KB,
+		TotalRetainedKB: total,
+		Chunks:          chunks,
+		ServerType:      "go",
+		SchemaVersion:   outputSchemaVersion,
+	}, nil
+}
+
+func handleSpawnGoroutines(ctx context.Context, req *mcp.CallToolRequest, args SpawnGoroutinesArgs) (*mcp.CallToolResult, SpawnGoroutinesOutput, error) {
+	if args.Count < 1 || args.Count > 1000000 {
+		return nil, SpawnGoroutinesOutput{}, fmt.Errorf("count deve estar entre 1 e 1000000")
+	}
+
+	var wg sync.WaitGroup
+	var checksum atomic.Int64
+	startTime := time.Now()
+	wg.Add(args.Count)
+	for i := 0; i < args.Count; i++ {
+		go func(v int64) {
+			defer wg.Done()
+			checksum.Add(v)
+		}(int64(i))
+	}
+	wg.Wait()
+	elapsed := time.Since(startTime)
+
+	return nil, SpawnGoroutinesOutput{
+		Count:            args.Count,
+		ElapsedMs:        float64(elapsed.Nanoseconds()) / 1e6,
+		GoroutinesPerSec: float64(args.Count) / elapsed.Seconds(),
+		Checksum:         checksum.Load(),
+		ServerType:       "go",
+		SchemaVersion:    outputSchemaVersion,
+	}, nil
+}
+
+// rampLimiter is a token bucket on /mcp whose rate grows linearly from a low
+// starting point to maxRPS over the ramp window, so benchmark runs don't slam
+// a cold server with full load instantly. With perClient set every client
+// gets its own bucket at that rate instead of sharing one.
+type rampLimiter struct {
+	limiter  *rate.Limiter
+	started  time.Time
+	startRPS float64
+	maxRPS   float64
+	maxBurst int
+	ramp     time.Duration
+
+	perClient bool
+	mu        sync.Mutex
+	clients   map[string]*clientLimiter
+}
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Per-client buckets idle this long are dropped; by then they would have
+// refilled completely, so recreating one later changes nothing.
+const clientLimiterIdle = 5 * time.Minute
+
+func newRampLimiter(maxRPS, burst int, ramp time.Duration, perClient bool) *rampLimiter {
+	l := &rampLimiter{
+		started:   time.Now(),
+		startRPS:  max(float64(maxRPS)/10, 1),
+		maxRPS:    float64(maxRPS),
+		maxBurst:  burst,
+		ramp:      ramp,
+		perClient: perClient,
+		clients:   make(map[string]*clientLimiter),
+	}
+	limit := l.limitAt(l.started)
+	l.limiter = rate.NewLimiter(limit, l.burstFor(limit))
+	if ramp > 0 {
+		go l.run()
+	}
+	if perClient {
+		go l.evictLoop()
+	}
+	return l
+}
+
+// clientKey identifies the caller by Authorization header, falling back to the remote IP
+func clientKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		return "auth:" + auth
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// allow reports whether r fits in its bucket: the shared one, or the
+// client's own when rate limiting is per client
+func (l *rampLimiter) allow(r *http.Request) bool {
+	if !l.perClient {
+		return l.limiter.Allow()
+	}
+	key := clientKey(r)
+	now := time.Now()
+	l.mu.Lock()
+	c, ok := l.clients[key]
+	if !ok {
+		limit := l.limitAt(now)
+		c = &clientLimiter{limiter: rate.NewLimiter(limit, l.burstFor(limit))}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+	l.mu.Unlock()
+	return c.limiter.Allow()
+}
+
+// evictLoop drops per-client buckets that have been idle for clientLimiterIdle
+func (l *rampLimiter) evictLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		l.mu.Lock()
+		for key, c := range l.clients {
+			if now.Sub(c.lastSeen) > clientLimiterIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// setLimit applies limit to the shared bucket and every per-client bucket
+func (l *rampLimiter) setLimit(limit rate.Limit) {
+	burst := l.burstFor(limit)
+	l.limiter.SetLimit(limit)
+	l.limiter.SetBurst(burst)
+	l.mu.Lock()
+	for _, c := range l.clients {
+		c.limiter.SetLimit(limit)
+		c.limiter.SetBurst(burst)
+	}
+	l.mu.Unlock()
+}
+
+// limitAt returns the allowed rate at time t
+func (l *rampLimiter) limitAt(t time.Time) rate.Limit {
+	elapsed := t.Sub(l.started)
+	if l.ramp <= 0 || elapsed >= l.ramp {
+		return rate.Limit(l.maxRPS)
+	}
+	progress := float64(elapsed) / float64(l.ramp)
+	return rate.Limit(l.startRPS + (l.maxRPS-l.startRPS)*progress)
+}
+
+// burstFor scales the burst with the rate, so a full bucket can't bypass the ramp
+func (l *rampLimiter) burstFor(limit rate.Limit) int {
+	return max(int(float64(l.maxBurst)*float64(limit)/l.maxRPS), 1)
+}
+
+// run raises the limiter's rate once per second until the ramp completes
+func (l *rampLimiter) run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		limit := l.limitAt(now)
+		l.setLimit(limit)
+		if limit >= rate.Limit(l.maxRPS) {
+			fmt.Printf("Rate limit ramp complete: %.0f req/s\n", l.maxRPS)
+			return
+		}
+		if int(now.Sub(l.started).Seconds())%5 == 0 {
+			fmt.Printf("Rate limit ramp: %.1f req/s allowed\n", float64(limit))
+		}
+	}
+}
+
+// rateLimitMiddleware rejects requests beyond the limiter's current rate with 429
+func rateLimitMiddleware(l *rampLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.allow(r) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":"limite de requisições excedido","server_type":"go"}`))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// arctanInv computes arctan(1/x) scaled by unity, using the Taylor series
+// 1/x - 1/(3x^3) + 1/(5x^5) - ...
+func arctanInv(x int64, unity *big.Int) *big.Int {
+	term := new(big.Int).Div(unity, big.NewInt(x))
+	sum := new(big.Int).Set(term)
+	x2 := big.NewInt(x * x)
+	t := new(big.Int)
+	for n, add := int64(3), false; term.Sign() != 0; n, add = n+2, !add {
+		term.Div(term, x2)
+		t.Div(term, big.NewInt(n))
+		if add {
+			sum.Add(sum, t)
+		} else {
+			sum.Sub(sum, t)
+		}
+	}
+	return sum
+}
+
+func handlePiDigits(ctx context.Context, req *mcp.CallToolRequest, args PiDigitsArgs) (*mcp.CallToolResult, PiDigitsOutput, error) {
+	if args.Digits < 1 || args.Digits > 20000 {
+		return nil, PiDigitsOutput{}, fmt.Errorf("digits deve estar entre 1 e 20000")
+	}
+
+	startTime := time.Now()
+
+	// Machin's formula: pi = 16*arctan(1/5) - 4*arctan(1/239), in fixed point
+	// with 10 guard digits to absorb truncation error
+	const guard = 10
+	unity := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(args.Digits+guard)), nil)
+	pi := new(big.Int).Mul(arctanInv(5, unity), big.NewInt(16))
+	pi.Sub(pi, new(big.Int).Mul(arctanInv(239, unity), big.NewInt(4)))
+	pi.Div(pi, new(big.Int).Exp(big.NewInt(10), big.NewInt(guard), nil))
+
+	digits := pi.String()
+
+	return nil, PiDigitsOutput{
+		Digits:        args.Digits,
+		Pi:            digits[:1] + "." + digits[1:],
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleLockContention(ctx context.Context, req *mcp.CallToolRequest, args LockContentionArgs) (*mcp.CallToolResult, LockContentionOutput, error) {
+	if args.Goroutines < 1 || args.Goroutines > 1000 {
+		return nil, LockContentionOutput{}, fmt.Errorf("goroutines deve estar entre 1 e 1000")
+	}
+	if args.Iterations < 1 || args.Iterations > 1000000 {
+		return nil, LockContentionOutput{}, fmt.Errorf("iterations deve estar entre 1 e 1000000")
+	}
+	expected := int64(args.Goroutines) * int64(args.Iterations)
+	if expected > 100000000 {
+		return nil, LockContentionOutput{}, fmt.Errorf("goroutines * iterations deve ser no máximo 100000000")
+	}
+
+	var increment func()
+	var counter int64
+	var atomicCounter atomic.Int64
+	switch args.Primitive {
+	case "mutex":
+		var mu sync.Mutex
+		increment = func() {
+			mu.Lock()
+			counter++
+			mu.Unlock()
+		}
+	case "rwmutex":
+		var mu sync.RWMutex
+		increment = func() {
+			mu.Lock()
+			counter++
+			mu.Unlock()
+		}
+	case "atomic":
+		increment = func() { atomicCounter.Add(1) }
+	default:
+		return nil, LockContentionOutput{}, fmt.Errorf("primitive deve ser mutex, rwmutex ou atomic")
+	}
+
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	wg.Add(args.Goroutines)
+	for g := 0; g < args.Goroutines; g++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < args.Iterations; i++ {
+				increment()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(startTime)
+
+	final := counter + atomicCounter.Load()
+	if final != expected {
+		return nil, LockContentionOutput{}, fmt.Errorf("contagem final %d difere da esperada %d", final, expected)
+	}
+
+	return nil, LockContentionOutput{
+		Primitive:     args.Primitive,
+		Goroutines:    args.Goroutines,
+		Iterations:    args.Iterations,
+		FinalCount:    final,
+		ExpectedCount: expected,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		OpsPerSec:     float64(expected) / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// Tool-call metrics, shared by every server instance in the process
+type toolStats struct {
+	Count   int64
+	Errors  int64
+	TotalNs int64
+	MaxNs   int64
+	// Latency ring buffer for /report, bounded by REPORT_SAMPLES; next is
+	// the slot to overwrite once it is full
+	Samples []int64
+	next    int
+}
+
+type metricsRegistry struct {
+	mu      sync.Mutex
+	started time.Time
+	tools   map[string]*toolStats
+	// Last recentWindow tool-call latencies across all tools, for load_info
+	recent     [recentWindow]int64
+	recentLen  int
+	recentNext int
+	// Bytes moved by /mcp POSTs: all of them, and per tool for tools/call
+	payloadTotal payloadStats
+	payloads     map[string]*payloadStats
+}
+
+type payloadStats struct {
+	Count         int64
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+func (p *payloadStats) add(in, out int64) {
+	p.Count++
+	p.RequestBytes += in
+	p.ResponseBytes += out
+}
+
+const recentWindow = 256
+
+var metrics = &metricsRegistry{started: time.Now(), tools: make(map[string]*toolStats), payloads: make(map[string]*payloadStats)}
+
+func (m *metricsRegistry) record(tool string, d time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.tools[tool]
+	if !ok {
+		st = &toolStats{}
+		m.tools[tool] = st
+	}
+	st.Count++
+	if failed {
+		st.Errors++
+	}
+	st.TotalNs += d.Nanoseconds()
+	st.MaxNs = max(st.MaxNs, d.Nanoseconds())
+	m.recent[m.recentNext] = d.Nanoseconds()
+	m.recentNext = (m.recentNext + 1) % recentWindow
+	m.recentLen = min(m.recentLen+1, recentWindow)
+	if cfg.ReportSamples > 0 {
+		if len(st.Samples) < cfg.ReportSamples {
+			st.Samples = append(st.Samples, d.Nanoseconds())
+		} else {
+			st.Samples[st.next] = d.Nanoseconds()
+			st.next = (st.next + 1) % cfg.ReportSamples
+		}
+	}
+}
+
+// reset discards every tool's counters and samples and restarts the clock,
+// marking the start of a new benchmark run
+func (m *metricsRegistry) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started = time.Now()
+	m.tools = make(map[string]*toolStats)
+	m.recentLen, m.recentNext = 0, 0
+	m.payloadTotal = payloadStats{}
+	m.payloads = make(map[string]*payloadStats)
+}
+
+// recordPayload adds one /mcp POST's body sizes; tool is empty for anything
+// other than a single tools/call frame naming a known tool
+func (m *metricsRegistry) recordPayload(tool string, in, out int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloadTotal.add(in, out)
+	if tool == "" {
+		return
+	}
+	p, ok := m.payloads[tool]
+	if !ok {
+		p = &payloadStats{}
+		m.payloads[tool] = p
+	}
+	p.add(in, out)
+}
+
+// recentAvgMs averages the latencies in the recent window
+func (m *metricsRegistry) recentAvgMs() (float64, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.recentLen == 0 {
+		return 0, 0
+	}
+	var total int64
+	for _, ns := range m.recent[:m.recentLen] {
+		total += ns
+	}
+	return float64(total) / float64(m.recentLen) / 1e6, m.recentLen
+}
+
+type ToolMetrics struct {
+	Tool   string  `json:"tool"`
+	Count  int64   `json:"count"`
+	Errors int64   `json:"errors"`
+	AvgMs  float64 `json:"avg_ms"`
+	MaxMs  float64 `json:"max_ms"`
+	PayloadMetrics
+}
+
+type PayloadMetrics struct {
+	RequestBytes     int64   `json:"request_bytes"`
+	ResponseBytes    int64   `json:"response_bytes"`
+	AvgRequestBytes  float64 `json:"avg_request_bytes"`
+	AvgResponseBytes float64 `json:"avg_response_bytes"`
+}
+
+func (p *payloadStats) metrics() PayloadMetrics {
+	if p == nil || p.Count == 0 {
+		return PayloadMetrics{}
+	}
+	return PayloadMetrics{
+		RequestBytes:     p.RequestBytes,
+		ResponseBytes:    p.ResponseBytes,
+		AvgRequestBytes:  float64(p.RequestBytes) / float64(p.Count),
+		AvgResponseBytes: float64(p.ResponseBytes) / float64(p.Count),
+	}
+}
+
+type MetricsSnapshot struct {
+	Timestamp       string         `json:"timestamp"`
+	UptimeSeconds   float64        `json:"uptime_seconds"`
+	TotalRequests   int64          `json:"total_requests"`
+	TotalErrors     int64          `json:"total_errors"`
+	RequestsPerSec  float64        `json:"requests_per_sec"`
+	MalformedFrames int64          `json:"malformed_frames"`
+	RecoveredPanics int64          `json:"recovered_panics"`
+	WriteTimeouts   int64          `json:"write_timeouts"`
+	Payload         PayloadMetrics `json:"payload"`
+	Tools           []ToolMetrics  `json:"tools"`
+	ServerType      string         `json:"server_type"`
+}
+
+func (m *metricsRegistry) snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	uptime := time.Since(m.started).Seconds()
+	snap := MetricsSnapshot{
+		Timestamp:       time.Now().UTC().Format(time.RFC3339Nano),
+		UptimeSeconds:   uptime,
+		MalformedFrames: malformedFrames.Load(),
+		RecoveredPanics: recoveredPanics.Load(),
+		WriteTimeouts:   writeTimeouts.Load(),
+		Payload:         m.payloadTotal.metrics(),
+		Tools:           make([]ToolMetrics, 0, len(m.tools)),
+		ServerType:      "go",
+	}
+	for name, st := range m.tools {
+		snap.TotalRequests += st.Count
+		snap.TotalErrors += st.Errors
+		snap.Tools = append(snap.Tools, ToolMetrics{
+			Tool:           name,
+			Count:          st.Count,
+			Errors:         st.Errors,
+			AvgMs:          float64(st.TotalNs) / float64(st.Count) / 1e6,
+			MaxMs:          float64(st.MaxNs) / 1e6,
+			PayloadMetrics: m.payloads[name].metrics(),
+		})
+	}
+	sort.Slice(snap.Tools, func(i, j int) bool { return snap.Tools[i].Tool < snap.Tools[j].Tool })
+	if uptime > 0 {
+		snap.RequestsPerSec = float64(snap.TotalRequests) / uptime
+	}
+	return snap
+}
+
+type ToolReport struct {
+	Tool          string  `json:"tool"`
+	Count         int64   `json:"count"`
+	Samples       int     `json:"samples"`
+	P50Ms         float64 `json:"p50_ms"`
+	P90Ms         float64 `json:"p90_ms"`
+	P99Ms         float64 `json:"p99_ms"`
+	MaxMs         float64 `json:"max_ms"`
+	ThroughputRPS float64 `json:"throughput_rps"`
+}
+
+type RunReport struct {
+	StartedAt       string       `json:"started_at"`
+	DurationSeconds float64      `json:"duration_seconds"`
+	SampleCapacity  int          `json:"sample_capacity"`
+	Tools           []ToolReport `json:"tools"`
+	ServerType      string       `json:"server_type"`
+}
+
+// percentileMs returns the nearest-rank percentile of sorted nanosecond samples
+func percentileMs(sorted []int64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return float64(sorted[max(rank-1, 0)]) / 1e6
+}
+
+// report computes latency percentiles per tool over the retained samples;
+// count, max and throughput cover every call since the last reset
+func (m *metricsRegistry) report() RunReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	duration := time.Since(m.started).Seconds()
+	rep := RunReport{
+		StartedAt:       m.started.UTC().Format(time.RFC3339Nano),
+		DurationSeconds: duration,
+		SampleCapacity:  cfg.ReportSamples,
+		Tools:           make([]ToolReport, 0, len(m.tools)),
+		ServerType:      "go",
+	}
+	for name, st := range m.tools {
+		sorted := slices.Clone(st.Samples)
+		slices.Sort(sorted)
+		tr := ToolReport{
+			Tool:    name,
+			Count:   st.Count,
+			Samples: len(sorted),
+			P50Ms:   percentileMs(sorted, 0.50),
+			P90Ms:   percentileMs(sorted, 0.90),
+			P99Ms:   percentileMs(sorted, 0.99),
+			MaxMs:   float64(st.MaxNs) / 1e6,
+		}
+		if duration > 0 {
+			tr.ThroughputRPS = float64(st.Count) / duration
+		}
+		rep.Tools = append(rep.Tools, tr)
+	}
+	sort.Slice(rep.Tools, func(i, j int) bool { return rep.Tools[i].Tool < rep.Tools[j].Tool })
+	return rep
+}
+
+func handleReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics.report())
+}
+
+// handleMetricsReset starts a new run: /metrics counters and /report samples
+// are cleared together so both describe the same window
+func handleMetricsReset(w http.ResponseWriter, r *http.Request) {
+	metrics.reset()
+	fmt.Println("Metrics reset")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"reset","server_type":"go"}`))
+}
+
+// toolName extracts the tool name from a tools/call request
+func toolName(req mcp.Request) string {
+	if p, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok && p != nil {
+		return p.Name
+	}
+	return ""
+}
+
+// metricsMiddleware records latency and outcome of every tools/call
+func metricsMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" {
+			return next(ctx, method, req)
+		}
+		startTime := time.Now()
+		res, err := next(ctx, method, req)
+		failed := err != nil
+		if r, ok := res.(*mcp.CallToolResult); ok && r != nil && r.IsError {
+			failed = true
+		}
+		// Names come from the client; only tools the server offers get a
+		// series, so made-up names can't grow the map
+		if name := toolName(req); knownTools[name] {
+			metrics.record(name, time.Since(startTime), failed)
+		}
+		return res, err
+	}
+}
+
+// requestLogMiddleware logs a LOG_SAMPLE_RATE fraction of successful MCP
+// requests; failures are always logged so sampling never hides them.
+func requestLogMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		startTime := time.Now()
+		res, err := next(ctx, method, req)
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		} else if r, ok := res.(*mcp.CallToolResult); ok && r != nil && r.IsError {
+			errMsg = "tool error"
+			if len(r.Content) > 0 {
+				if text, ok := r.Content[0].(*mcp.TextContent); ok {
+					errMsg = text.Text
+				}
+			}
+		}
+
+		target := method
+		if method == "tools/call" {
+			target += " " + toolName(req)
+		}
+		if errMsg != "" {
+			fmt.Fprintf(os.Stderr, "request %s failed after %.2fms: %s\n", target, elapsedMs(startTime), errMsg)
+		} else if cfg.LogSampleRate > 0 && rand.Float64() < cfg.LogSampleRate {
+			fmt.Printf("request %s ok in %.2fms\n", target, elapsedMs(startTime))
+		}
+		return res, err
+	}
+}
+
+// minResponseMiddleware pads tools/call up to MIN_RESPONSE_MS of wall-clock
+// service time, giving up early if the request is cancelled.
+func minResponseMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != "tools/call" || cfg.MinResponseMs <= 0 {
+			return next(ctx, method, req)
+		}
+		deadline := time.Now().Add(time.Duration(cfg.MinResponseMs) * time.Millisecond)
+		res, err := next(ctx, method, req)
+		if remaining := time.Until(deadline); remaining > 0 {
+			timer := time.NewTimer(remaining)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+		return res, err
+	}
+}
+
+// memoryHeavyTools are rejected while the heap is above MEM_SOFT_LIMIT_MB;
+// every other tool keeps being served.
+var memoryHeavyTools = map[string]bool{
+	"leak_memory":         true,
+	"bst_benchmark":       true,
+	"sort_numbers":        true,
+	"edit_distance":       true,
+	"append_bench":        true,
+	"memory_access_bench": true,
+	"map_churn":           true,
+	"vector_sum":          true,
+	"tree_traversal":      true,
+	"intern_bench":        true,
+	"sharded_map_bench":   true,
+	"finalizer_bench":     true,
+	"sort_types":          true,
+	"fixture_json":        true,
+	"memcopy_bench":       true,
+}
+
+// codeServerOverloaded is an implementation-defined JSON-RPC server error,
+// the protocol-level equivalent of HTTP 503
+const codeServerOverloaded = -32001
+
+var memoryDegraded atomic.Bool
+
+// watchMemory polls the heap and toggles degraded mode, clearing it only once
+// the heap falls below 90% of the limit so it doesn't flap at the threshold.
+func watchMemory(limitMB int) {
+	limit := uint64(limitMB) * 1024 * 1024
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var m runtime.MemStats
+	for range ticker.C {
+		runtime.ReadMemStats(&m)
+		switch {
+		case !memoryDegraded.Load() && m.HeapAlloc > limit:
+			memoryDegraded.Store(true)
+			fmt.Printf("Memory soft limit exceeded (heap %d MB > %d MB): rejecting memory-heavy tools\n", m.HeapAlloc>>20, limitMB)
+		case memoryDegraded.Load() && m.HeapAlloc < limit/10*9:
+			memoryDegraded.Store(false)
+			fmt.Printf("Memory back under soft limit (heap %d MB): memory-heavy tools re-enabled\n", m.HeapAlloc>>20)
+		}
+	}
+}
+
+// memoryGuardError rejects the named tool if it is memory-heavy and degraded
+// mode is active
+func memoryGuardError(name string) error {
+	if memoryDegraded.Load() && memoryHeavyTools[name] {
+		return &jsonrpc.Error{
+			Code:    codeServerOverloaded,
+			Message: fmt.Sprintf("serviço indisponível: memória acima do limite de %d MB, tente novamente mais tarde", cfg.MemSoftLimitMB),
+		}
+	}
+	return nil
+}
+
+// memoryGuardMiddleware rejects memory-heavy tools while degraded mode is active
+func memoryGuardMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "tools/call" {
+			if err := memoryGuardError(toolName(req)); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// goroutineSpawningTools start goroutines of their own and are rejected while
+// the process is above MAX_GOROUTINES
+var goroutineSpawningTools = map[string]bool{
+	"spawn_goroutines":  true,
+	"lock_contention":   true,
+	"counter_bench":     true,
+	"sharded_map_bench": true,
+	"fetch_parallel":    true,
+	"rng_bench":         true,
+	"mandelbrot":        true,
+	"factorize":         true,
+	"batch":             true,
+	"pingpong":          true,
+	"rwmutex_bench":     true,
+	"worker_pool_bench": true,
+}
+
+var goroutinesDegraded atomic.Bool
+
+// watchGoroutines polls the goroutine count with the same 90% hysteresis as
+// watchMemory
+func watchGoroutines(limit int) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		n := runtime.NumGoroutine()
+		switch {
+		case !goroutinesDegraded.Load() && n > limit:
+			goroutinesDegraded.Store(true)
+			fmt.Printf("Goroutine ceiling exceeded (%d > %d): rejecting goroutine-spawning tools\n", n, limit)
+		case goroutinesDegraded.Load() && n < limit/10*9:
+			goroutinesDegraded.Store(false)
+			fmt.Printf("Goroutines back under ceiling (%d): goroutine-spawning tools re-enabled\n", n)
+		}
+	}
+}
+
+// goroutineGuardError rejects the named tool if it spawns goroutines and the
+// goroutine ceiling is exceeded
+func goroutineGuardError(name string) error {
+	if goroutinesDegraded.Load() && goroutineSpawningTools[name] {
+		return &jsonrpc.Error{
+			Code:    codeServerOverloaded,
+			Message: fmt.Sprintf("serviço indisponível: goroutines acima do limite de %d, tente novamente mais tarde", cfg.MaxGoroutines),
+		}
+	}
+	return nil
+}
+
+// goroutineGuardMiddleware rejects goroutine-spawning tools while the
+// goroutine ceiling is exceeded
+func goroutineGuardMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "tools/call" {
+			if err := goroutineGuardError(toolName(req)); err != nil {
+				return nil, err
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// toolGuardError applies both guards to a tool invoked in-process, which
+// never passes through the receiving middleware
+func toolGuardError(name string) error {
+	if err := memoryGuardError(name); err != nil {
+		return err
+	}
+	return goroutineGuardError(name)
+}
+
+// configView maps every Config field to its env var name with the effective
+// value, after defaults and derived values (e.g. RATE_LIMIT_BURST) apply
+func configView(c Config) map[string]interface{} {
+	view := make(map[string]interface{})
+	v := reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		switch value := v.Field(i).Interface().(type) {
+		case map[string]bool:
+			names := make([]string, 0, len(value))
+			for n := range value {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			view[name] = names
+		case time.Time:
+			if value.IsZero() {
+				view[name] = ""
+			} else {
+				view[name] = value.Format(time.RFC3339)
+			}
+		default:
+			view[name] = value
+		}
+	}
+	return view
+}
+
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config":      configView(cfg),
+		"server_type": "go",
+	})
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics.snapshot())
+}
+
+// streamsCtx is cancelled when shutdown begins, so long-lived streams end
+// instead of holding the drain open for the whole grace period
+var streamsCtx, cancelStreams = context.WithCancel(context.Background())
+
+// handlersCtx is cancelled if the shutdown grace period expires, so cut-off
+// work stops. Request contexts derive from it through BaseContext; tool calls
+// don't, as the SDK detaches them from the HTTP request, so the tool handler
+// wrappers watch it too.
+var handlersCtx, cancelHandlers = context.WithCancel(context.Background())
+
+// streamShutdownMiddleware ends MCP GET streams once shutdown begins; the SDK
+// keeps them open until the request context is done. POSTs keep their
+// context so in-flight tool calls can still drain.
+func streamShutdownMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(streamsCtx, cancel)
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// handleMetricsStream pushes a metrics snapshot every METRICS_STREAM_INTERVAL_MS
+// as Server-Sent Events until the client disconnects or shutdown begins.
+func handleMetricsStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming não suportado", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(time.Duration(cfg.MetricsStreamMs) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		data, err := json.Marshal(metrics.snapshot())
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: metrics\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-streamsCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func handleEnqueue(ctx context.Context, req *mcp.CallToolRequest, args EnqueueArgs) (*mcp.CallToolResult, QueueOpOutput, error) {
+	if args.TimeoutMs < 0 || args.TimeoutMs > 30000 {
+		return nil, QueueOpOutput{}, fmt.Errorf("timeout_ms deve estar entre 0 e 30000")
+	}
+
+	startTime := time.Now()
+	ok := false
+	if args.TimeoutMs == 0 {
+		// Fail fast when full
+		select {
+		case workQueue <- args.Value:
+			ok = true
+		default:
+		}
+	} else {
+		// Block until space frees up, the timeout expires or the request is cancelled
+		timer := time.NewTimer(time.Duration(args.TimeoutMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case workQueue <- args.Value:
+			ok = true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	return nil, QueueOpOutput{
+		Op:            "enqueue",
+		Value:         args.Value,
+		Ok:            ok,
+		Depth:         len(workQueue),
+		Capacity:      cap(workQueue),
+		LatencyNs:     time.Since(startTime).Nanoseconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleDequeue(ctx context.Context, req *mcp.CallToolRequest, args DequeueArgs) (*mcp.CallToolResult, QueueOpOutput, error) {
+	if args.TimeoutMs < 0 || args.TimeoutMs > 30000 {
+		return nil, QueueOpOutput{}, fmt.Errorf("timeout_ms deve estar entre 0 e 30000")
+	}
+
+	startTime := time.Now()
+	var value string
+	ok := false
+	if args.TimeoutMs == 0 {
+		select {
+		case value = <-workQueue:
+			ok = true
+		default:
+		}
+	} else {
+		timer := time.NewTimer(time.Duration(args.TimeoutMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case value = <-workQueue:
+			ok = true
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	return nil, QueueOpOutput{
+		Op:            "dequeue",
+		Value:         value,
+		Ok:            ok,
+		Depth:         len(workQueue),
+		Capacity:      cap(workQueue),
+		LatencyNs:     time.Since(startTime).Nanoseconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleStructMap(ctx context.Context, req *mcp.CallToolRequest, args StructMapArgs) (*mcp.CallToolResult, StructMapOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 100000 {
+		return nil, StructMapOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000")
+	}
+
+	payload, err := json.Marshal(args.Data)
+	if err != nil {
+		return nil, StructMapOutput{}, fmt.Errorf("data inválido: %v", err)
+	}
+
+	var record StructMapRecord
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		record = StructMapRecord{}
+		if err := json.Unmarshal(payload, &record); err != nil {
+			return nil, StructMapOutput{}, fmt.Errorf("data incompatível com a estrutura: %v", err)
+		}
+		if payload, err = json.Marshal(record); err != nil {
+			return nil, StructMapOutput{}, fmt.Errorf("falha ao serializar: %v", err)
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, StructMapOutput{
+		Iterations:    args.Iterations,
+		PayloadBytes:  len(payload),
+		Record:        record,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		PerIterNs:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// namedLayouts lets clients in other languages use layout names instead of
+// Go's reference-time syntax; anything else is treated as a Go layout.
+var namedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"ANSIC":       time.ANSIC,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+func resolveLayout(layout string) string {
+	if named, ok := namedLayouts[layout]; ok {
+		return named
+	}
+	return layout
+}
+
+func handleTimeFormat(ctx context.Context, req *mcp.CallToolRequest, args TimeFormatArgs) (*mcp.CallToolResult, TimeFormatOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 1000000 {
+		return nil, TimeFormatOutput{}, fmt.Errorf("iterations deve estar entre 1 e 1000000")
+	}
+	if args.Layout == "" || args.OutputLayout == "" {
+		return nil, TimeFormatOutput{}, fmt.Errorf("layout e output_layout são obrigatórios")
+	}
+	layout, outputLayout := resolveLayout(args.Layout), resolveLayout(args.OutputLayout)
+	if _, err := time.Parse(layout, args.Input); err != nil {
+		return nil, TimeFormatOutput{}, fmt.Errorf("não foi possível interpretar a data: %v", err)
+	}
+
+	var result string
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		t, _ := time.Parse(layout, args.Input)
+		result = t.Format(outputLayout)
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, TimeFormatOutput{
+		Input:         args.Input,
+		Result:        result,
+		Iterations:    args.Iterations,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		PerIterNs:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// connTracker counts connections per http.ConnState across every listener.
+// ConnState only reports the new state, so the last state of each connection
+// is remembered to decrement the right gauge on transition.
+type connTracker struct {
+	states   sync.Map // net.Conn -> http.ConnState
+	newConns atomic.Int64
+	active   atomic.Int64
+	idle     atomic.Int64
+	accepted atomic.Int64
+	closed   atomic.Int64
+	hijacked atomic.Int64
+}
+
+var conns = &connTracker{}
+
+func (t *connTracker) gauge(state http.ConnState) *atomic.Int64 {
+	switch state {
+	case http.StateNew:
+		return &t.newConns
+	case http.StateActive:
+		return &t.active
+	case http.StateIdle:
+		return &t.idle
+	}
+	return nil
+}
+
+func (t *connTracker) onStateChange(c net.Conn, state http.ConnState) {
+	if prev, ok := t.states.Load(c); ok {
+		if g := t.gauge(prev.(http.ConnState)); g != nil {
+			g.Add(-1)
+		}
+	}
+	switch state {
+	case http.StateNew:
+		t.accepted.Add(1)
+	case http.StateClosed:
+		t.closed.Add(1)
+	case http.StateHijacked:
+		t.hijacked.Add(1)
+	}
+	if g := t.gauge(state); g != nil {
+		g.Add(1)
+		t.states.Store(c, state)
+	} else {
+		t.states.Delete(c)
+	}
+}
+
+func handleConns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"new":            conns.newConns.Load(),
+		"active":         conns.active.Load(),
+		"idle":           conns.idle.Load(),
+		"total_accepted": conns.accepted.Load(),
+		"total_closed":   conns.closed.Load(),
+		"total_hijacked": conns.hijacked.Load(),
+		"server_type":    "go",
+	})
+}
+
+// malformedFrames counts POST bodies on /mcp rejected before reaching the SDK
+var malformedFrames atomic.Int64
+
+// frameError is the JSON-RPC error envelope returned for malformed frames.
+// reason says what was wrong in a form a harness developer can act on.
+type frameError struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   struct {
+		Code    int64  `json:"code"`
+		Message string `json:"message"`
+		Data    struct {
+			Reason     string `json:"reason"`
+			ServerType string `json:"server_type"`
+		} `json:"data"`
+	} `json:"error"`
+}
+
+// checkFrame validates the JSON-RPC envelope of one message. It returns the
+// message id when one could be read, so the error can be correlated, and the
+// tool named by a tools/call so payload metrics needn't parse the body again.
+func checkFrame(raw json.RawMessage) (id json.RawMessage, tool string, code int64, reason string) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, "", jsonrpc.CodeInvalidRequest, "a mensagem deve ser um objeto JSON"
+	}
+	id = msg["id"]
+	var version string
+	if err := json.Unmarshal(msg["jsonrpc"], &version); err != nil || version != "2.0" {
+		return id, "", jsonrpc.CodeInvalidRequest, `campo "jsonrpc" deve ser "2.0"`
+	}
+	if method, ok := msg["method"]; ok {
+		var name string
+		if err := json.Unmarshal(method, &name); err != nil || name == "" {
+			return id, "", jsonrpc.CodeInvalidRequest, `campo "method" deve ser uma string não vazia`
+		}
+		if name == "tools/call" {
+			var params struct {
+				Name string `json:"name"`
+			}
+			json.Unmarshal(msg["params"], &params)
+			tool = params.Name
+		}
+		return id, tool, 0, ""
+	}
+	// Without a method it must be a client response to a server request
+	_, hasResult := msg["result"]
+	_, hasError := msg["error"]
+	if id == nil || hasResult == hasError {
+		return id, "", jsonrpc.CodeInvalidRequest, `a mensagem deve ter "method", ou "id" com exatamente um de "result"/"error"`
+	}
+	return id, "", 0, ""
+}
+
+// readLimitedBody reads the whole request body, up to MAX_BODY_BYTES. On
+// failure it has already answered with a 413 or 400 and returns false.
+func readLimitedBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(cfg.MaxBodyBytes)))
+	r.Body.Close()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("corpo da requisição excede MAX_BODY_BYTES (%d bytes)", cfg.MaxBodyBytes), http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, "falha ao ler o corpo da requisição", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+// frameValidationMiddleware rejects POST bodies that aren't well-formed
+// JSON-RPC with a 400 and a descriptive error envelope, counting and
+// optionally logging each one. Bodies over MAX_BODY_BYTES get a 413 before
+// they are fully buffered. Valid frames pass through untouched.
+func frameValidationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, ok := readLimitedBody(w, r)
+		if !ok {
+			return
+		}
+
+		var id json.RawMessage
+		var tool string
+		var code int64
+		var reason string
+		trimmed := bytes.TrimSpace(body)
+		switch {
+		case !json.Valid(body):
+			code, reason = jsonrpc.CodeParseError, "corpo não é JSON válido"
+		case len(trimmed) > 0 && trimmed[0] == '[':
+			var batch []json.RawMessage
+			json.Unmarshal(body, &batch)
+			if len(batch) == 0 {
+				code, reason = jsonrpc.CodeInvalidRequest, "lote JSON-RPC vazio"
+			}
+			for i, msg := range batch {
+				if _, _, c, why := checkFrame(msg); c != 0 {
+					code, reason = c, fmt.Sprintf("item %d do lote: %s", i, why)
+					break
+				}
+			}
+		default:
+			id, tool, code, reason = checkFrame(body)
+		}
+
+		if code != 0 {
+			total := malformedFrames.Add(1)
+			if cfg.LogMalformed {
+				fmt.Fprintf(os.Stderr, "Malformed MCP frame from %s (%d so far): %s\n", r.RemoteAddr, total, reason)
+			}
+			resp := frameError{JSONRPC: "2.0", ID: id}
+			if resp.ID == nil {
+				resp.ID = json.RawMessage("null")
+			}
+			resp.Error.Code = code
+			resp.Error.Message = "frame JSON-RPC malformado"
+			resp.Error.Data.Reason = reason
+			resp.Error.Data.ServerType = "go"
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		// Unknown names only count towards the totals
+		if knownTools[tool] {
+			r = r.WithContext(context.WithValue(r.Context(), frameToolKey{}, tool))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// frameToolKey carries the tool named by a single tools/call frame from
+// frameValidationMiddleware to payloadMetricsMiddleware
+type frameToolKey struct{}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// payloadMetricsMiddleware counts the request and response body bytes of
+// every /mcp POST as they stream through. GETs are left out: their SSE
+// streams stay open for the whole session.
+func payloadMetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		cw := &countingWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		tool, _ := r.Context().Value(frameToolKey{}).(string)
+		metrics.recordPayload(tool, body.n, cw.n)
+	})
+}
+
+// chunkingWriter splits every response write into size-byte pieces, flushing
+// each one and pausing delay in between, to mimic a slow fragmented network.
+type chunkingWriter struct {
+	http.ResponseWriter
+	ctx   context.Context
+	size  int
+	delay time.Duration
+}
+
+func (w *chunkingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(w.size, len(p))
+		m, err := w.ResponseWriter.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		w.Flush()
+		p = p[n:]
+		if len(p) > 0 && w.delay > 0 {
+			select {
+			case <-time.After(w.delay):
+			case <-w.ctx.Done():
+				return written, w.ctx.Err()
+			}
+		}
+	}
+	return written, nil
+}
+
+func (w *chunkingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *chunkingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// chunkingMiddleware fragments /mcp responses when CHUNK_SIZE is set
+func chunkingMiddleware(size int, delay time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&chunkingWriter{ResponseWriter: w, ctx: r.Context(), size: size, delay: delay}, r)
+	})
+}
+
+// recoveredPanics counts panics caught by recoverMiddleware and the tool
+// handler wrappers
+var recoveredPanics atomic.Int64
+
+// recoverMiddleware turns a panic inside the SDK's HTTP handler into a 500 and
+// a logged stack trace, so one bad edge case doesn't end a long benchmark run.
+// Tool handlers run on goroutines the SDK starts itself, so addTool and
+// addRawTool recover those separately.
+func recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v) // deliberate abort, let net/http handle it
+			}
+			total := recoveredPanics.Add(1)
+			fmt.Fprintf(os.Stderr, "Recovered panic in MCP handler (%d so far) for %s %s: %v\n%s", total, r.Method, r.URL.Path, v, debug.Stack())
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"erro interno do servidor","server_type":"go"}`))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listenConfig applies the SO_REUSEPORT setting to listening sockets
+func listenConfig() net.ListenConfig {
+	return net.ListenConfig{
+		Control: func(network, address string, c syscall.RawConn) error {
+			if !cfg.SOReusePort {
+				return nil
+			}
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				sockErr = setReusePort(fd)
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+}
+
+// noDelayListener sets TCP_NODELAY on every accepted connection. Go enables
+// it by default, so this only matters when TCP_NODELAY=false turns Nagle on.
+type noDelayListener struct {
+	net.Listener
+	noDelay bool
+}
+
+func (l noDelayListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tc, ok := c.(*net.TCPConn); ok {
+		tc.SetNoDelay(l.noDelay)
+	}
+	return c, nil
+}
+
+// writeTimeouts counts responses abandoned because the client stopped reading
+var writeTimeouts atomic.Int64
+
+// writeDeadlineWriter gives every write and flush its own deadline. A plain
+// http.Server.WriteTimeout covers the whole response, which would cut off SSE
+// streams and long tool calls; this only fires when a client stops reading.
+type writeDeadlineWriter struct {
+	http.ResponseWriter
+	rc       *http.ResponseController
+	timeout  time.Duration
+	r        *http.Request
+	timedOut bool
+}
+
+func (w *writeDeadlineWriter) check(err error) {
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) && !w.timedOut {
+		w.timedOut = true
+		total := writeTimeouts.Add(1)
+		fmt.Fprintf(os.Stderr, "Write timeout after %s: closing slow client %s on %s %s (%d so far)\n",
+			w.timeout, w.r.RemoteAddr, w.r.Method, w.r.URL.Path, total)
+	}
+}
+
+func (w *writeDeadlineWriter) Write(p []byte) (int, error) {
+	w.rc.SetWriteDeadline(time.Now().Add(w.timeout))
+	n, err := w.ResponseWriter.Write(p)
+	w.check(err)
+	return n, err
+}
+
+func (w *writeDeadlineWriter) Flush() {
+	w.rc.SetWriteDeadline(time.Now().Add(w.timeout))
+	w.check(w.rc.Flush())
+}
+
+func (w *writeDeadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writeTimeoutMiddleware applies WRITE_TIMEOUT_MS to every response
+func writeTimeoutMiddleware(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&writeDeadlineWriter{ResponseWriter: w, rc: http.NewResponseController(w), timeout: timeout, r: r}, r)
+	})
+}
+
+// inFlight counts HTTP requests currently being served across every listener,
+// so shutdown can report how much work the grace period had to drain.
+var inFlight atomic.Int64
+
+func inFlightMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// resolveWalkPath maps a client path onto WALK_BASE_DIR and rejects anything
+// that escapes it, including via symlinks.
+func resolveWalkPath(path string) (string, error) {
+	target := path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(cfg.WalkBaseDir, target)
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return "", fmt.Errorf("caminho inválido: %v", err)
+	}
+	rel, err := filepath.Rel(cfg.WalkBaseDir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("caminho fora do diretório permitido %s", cfg.WalkBaseDir)
+	}
+	return resolved, nil
+}
+
+func handleWalkFilesystem(ctx context.Context, req *mcp.CallToolRequest, args WalkFilesystemArgs) (*mcp.CallToolResult, WalkFilesystemOutput, error) {
+	if args.MaxEntries < 1 || args.MaxEntries > 1000000 {
+		return nil, WalkFilesystemOutput{}, fmt.Errorf("max_entries deve estar entre 1 e 1000000")
+	}
+	root, err := resolveWalkPath(args.Path)
+	if err != nil {
+		return nil, WalkFilesystemOutput{}, err
+	}
+
+	out := WalkFilesystemOutput{Path: root, ServerType: "go", SchemaVersion: outputSchemaVersion}
+	startTime := time.Now()
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			// Unreadable entries are counted, not fatal
+			out.Errors++
+			return nil
+		}
+		if out.Files+out.Dirs >= args.MaxEntries {
+			out.Truncated = true
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			out.Dirs++
+			return nil
+		}
+		out.Files++
+		if info, err := d.Info(); err == nil {
+			out.TotalBytes += info.Size()
+		} else {
+			out.Errors++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, WalkFilesystemOutput{}, fmt.Errorf("varredura interrompida: %v", err)
+	}
+	out.ElapsedMs = elapsedMs(startTime)
+
+	return nil, out, nil
+}
+
+func handleBatch(ctx context.Context, req *mcp.CallToolRequest, args BatchArgs) (*mcp.CallToolResult, BatchOutput, error) {
+	if len(args.Calls) < 1 || len(args.Calls) > 100 {
+		return nil, BatchOutput{}, fmt.Errorf("calls deve conter entre 1 e 100 chamadas")
+	}
+
+	// Each call fails independently; its error is reported in its own slot
+	results := make([]BatchCallResult, len(args.Calls))
+	run := func(i int) {
+		call := args.Calls[i]
+		callStart := time.Now()
+		result := BatchCallResult{Tool: call.Tool}
+		if call.Tool == "batch" {
+			result.Error = "batch não pode ser aninhado"
+		} else if invoke, ok := toolInvokers[call.Tool]; !ok {
+			result.Error = fmt.Sprintf("ferramenta desconhecida: %s", call.Tool)
+		} else if err := toolGuardError(call.Tool); err != nil {
+			// Nested calls skip the receiving middleware, so each one faces
+			// the guards it would as a direct call
+			result.Error = err.Error()
+		} else if raw, err := json.Marshal(call.Args); err != nil {
+			result.Error = fmt.Sprintf("argumentos inválidos: %v", err)
+		} else if out, err := invoke(ctx, raw); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Ok = true
+			result.Result = out
+		}
+		result.ElapsedMs = elapsedMs(callStart)
+		results[i] = result
+	}
+
+	startTime := time.Now()
+	if args.Parallel {
+		var wg sync.WaitGroup
+		wg.Add(len(args.Calls))
+		for i := range args.Calls {
+			go func() {
+				defer wg.Done()
+				run(i)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for i := range args.Calls {
+			run(i)
+		}
+	}
+
+	out := BatchOutput{
+		Results:       results,
+		Parallel:      args.Parallel,
+		TotalMs:       elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}
+	for _, r := range results {
+		if r.Ok {
+			out.Succeeded++
+		} else {
+			out.Failed++
+		}
+	}
+	return nil, out, nil
+}
+
+// bstNode is an unbalanced binary search tree node; one heap allocation per
+// key, which is the point of the benchmark
+type bstNode struct {
+	key         int
+	left, right *bstNode
+}
+
+type bst struct {
+	root *bstNode
+	size int
+}
+
+func (t *bst) insert(key int) {
+	link := &t.root
+	for *link != nil {
+		switch {
+		case key < (*link).key:
+			link = &(*link).left
+		case key > (*link).key:
+			link = &(*link).right
+		default:
+			return
+		}
+	}
+	*link = &bstNode{key: key}
+	t.size++
+}
+
+func (t *bst) contains(key int) bool {
+	n := t.root
+	for n != nil {
+		switch {
+		case key < n.key:
+			n = n.left
+		case key > n.key:
+			n = n.right
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func (t *bst) delete(key int) {
+	link := &t.root
+	for *link != nil && (*link).key != key {
+		if key < (*link).key {
+			link = &(*link).left
+		} else {
+			link = &(*link).right
+		}
+	}
+	n := *link
+	if n == nil {
+		return
+	}
+	switch {
+	case n.left == nil:
+		*link = n.right
+	case n.right == nil:
+		*link = n.left
+	default:
+		// Replace with the in-order successor
+		succLink := &n.right
+		for (*succLink).left != nil {
+			succLink = &(*succLink).left
+		}
+		succ := *succLink
+		*succLink = succ.right
+		succ.left, succ.right = n.left, n.right
+		*link = succ
+	}
+	t.size--
+}
+
+func handleBSTBenchmark(ctx context.Context, req *mcp.CallToolRequest, args BSTBenchmarkArgs) (*mcp.CallToolResult, BSTBenchmarkOutput, error) {
+	if args.Operations < 1 || args.Operations > 1000000 {
+		return nil, BSTBenchmarkOutput{}, fmt.Errorf("operations deve estar entre 1 e 1000000")
+	}
+
+	rng := rand.New(rand.NewSource(args.Seed))
+	out := BSTBenchmarkOutput{Operations: args.Operations, Seed: args.Seed, ServerType: "go", SchemaVersion: outputSchemaVersion}
+	tree := &bst{}
+	startTime := time.Now()
+	// 50% insert, 30% lookup, 20% delete over a key space the size of the workload
+	for i := 0; i < args.Operations; i++ {
+		key := rng.Intn(args.Operations)
+		switch op := rng.Intn(10); {
+		case op < 5:
+			tree.insert(key)
+			out.Inserts++
+		case op < 8:
+			if tree.contains(key) {
+				out.LookupHits++
+			}
+			out.Lookups++
+		default:
+			tree.delete(key)
+			out.Deletes++
+		}
+	}
+	out.ElapsedMs = elapsedMs(startTime)
+	out.FinalNodes = tree.size
+
+	return nil, out, nil
+}
+
+// quicksort sorts xs[lo:hi+1] in place (median-of-three Lomuto partition),
+// recursing into the smaller side so stack depth stays O(log n). less and swap
+// are closures so the tool can count operations without a second
+// implementation; indices are always absolute, so swap is never rewrapped.
+func quicksort(xs []float64, lo, hi int, less func(a, b float64) bool, swap func(i, j int)) {
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if less(xs[mid], xs[lo]) {
+			swap(mid, lo)
+		}
+		if less(xs[hi], xs[lo]) {
+			swap(hi, lo)
+		}
+		if less(xs[mid], xs[hi]) {
+			swap(mid, hi)
+		}
+		pivot := xs[hi]
+		p := lo
+		for i := lo; i < hi; i++ {
+			if less(xs[i], pivot) {
+				swap(i, p)
+				p++
+			}
+		}
+		swap(p, hi)
+
+		if p-lo < hi-p {
+			quicksort(xs, lo, p-1, less, swap)
+			lo = p + 1
+		} else {
+			quicksort(xs, p+1, hi, less, swap)
+			hi = p - 1
+		}
+	}
+}
+
+// mergesort sorts xs using buf (same length) as scratch space; write is called
+// for every element written back into xs.
+func mergesort(xs, buf []float64, less func(a, b float64) bool, write func()) {
+	if len(xs) <= 1 {
+		return
+	}
+	mid := len(xs) / 2
+	mergesort(xs[:mid], buf[:mid], less, write)
+	mergesort(xs[mid:], buf[mid:], less, write)
+	copy(buf, xs)
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(xs) {
+		if less(buf[j], buf[i]) {
+			xs[k] = buf[j]
+			j++
+		} else {
+			xs[k] = buf[i]
+			i++
+		}
+		write()
+		k++
+	}
+	for ; i < mid; i, k = i+1, k+1 {
+		xs[k] = buf[i]
+		write()
+	}
+	for ; j < len(xs); j, k = j+1, k+1 {
+		xs[k] = buf[j]
+		write()
+	}
+}
+
+func handleSortNumbers(ctx context.Context, req *mcp.CallToolRequest, args SortNumbersArgs) (*mcp.CallToolResult, SortNumbersOutput, error) {
+	if args.Count < 1 || args.Count > 1000000 {
+		return nil, SortNumbersOutput{}, fmt.Errorf("count deve estar entre 1 e 1000000")
+	}
+	if args.Algorithm != "quicksort" && args.Algorithm != "mergesort" {
+		return nil, SortNumbersOutput{}, fmt.Errorf("algorithm deve ser quicksort ou mergesort")
+	}
+
+	rng := rand.New(rand.NewSource(args.Seed))
+	xs := make([]float64, args.Count)
+	for i := range xs {
+		xs[i] = rng.Float64() * 1e6
+	}
+
+	// Counting closures only when instrumented, so plain timings stay comparable
+	var comparisons, swaps int64
+	less := func(a, b float64) bool { return a < b }
+	countWrite := func() {}
+	if args.Instrument {
+		less = func(a, b float64) bool {
+			comparisons++
+			return a < b
+		}
+		countWrite = func() { swaps++ }
+	}
+
+	startTime := time.Now()
+	if args.Algorithm == "quicksort" {
+		quicksort(xs, 0, len(xs)-1, less, func(i, j int) {
+			xs[i], xs[j] = xs[j], xs[i]
+			countWrite()
+		})
+	} else {
+		mergesort(xs, make([]float64, len(xs)), less, countWrite)
+	}
+	elapsed := elapsedMs(startTime)
+
+	return nil, SortNumbersOutput{
+		Algorithm:     args.Algorithm,
+		Count:         args.Count,
+		Seed:          args.Seed,
+		Instrumented:  args.Instrument,
+		Comparisons:   comparisons,
+		Swaps:         swaps,
+		Sorted:        sort.Float64sAreSorted(xs),
+		ElapsedMs:     elapsed,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleEditDistance(ctx context.Context, req *mcp.CallToolRequest, args EditDistanceArgs) (*mcp.CallToolResult, EditDistanceOutput, error) {
+	a, b := []rune(args.A), []rune(args.B)
+	rows, cols := len(a)+1, len(b)+1
+	if len(a) > 5000 || len(b) > 5000 || rows*cols > 4000000 {
+		return nil, EditDistanceOutput{}, fmt.Errorf("strings muito longas: cada uma deve ter no máximo 5000 caracteres e a tabela no máximo 4000000 células")
+	}
+
+	startTime := time.Now()
+	// Full (rows x cols) Levenshtein DP table, flattened row-major
+	table := make([]int32, rows*cols)
+	for i := 0; i < rows; i++ {
+		table[i*cols] = int32(i)
+	}
+	for j := 0; j < cols; j++ {
+		table[j] = int32(j)
+	}
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			cost := int32(1)
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			table[i*cols+j] = min(
+				table[(i-1)*cols+j]+1,      // deletion
+				table[i*cols+j-1]+1,        // insertion
+				table[(i-1)*cols+j-1]+cost, // substitution
+			)
+		}
+	}
+
+	return nil, EditDistanceOutput{
+		Distance:      int(table[rows*cols-1]),
+		TableRows:     rows,
+		TableCols:     cols,
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// readCgroupFile returns the trimmed contents of a cgroup file, or "" if absent
+func readCgroupFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// cgroupLimits reads CPU (in cores) and memory limits, trying cgroup v2 first
+// and falling back to v1. Zero means unlimited or unknown.
+func cgroupLimits() (version string, cpuCores float64, memBytes int64) {
+	if cpuMax := readCgroupFile("/sys/fs/cgroup/cpu.max"); cpuMax != "" {
+		version = "v2"
+		// Format: "<quota> <period>" or "max <period>"
+		if fields := strings.Fields(cpuMax); len(fields) == 2 && fields[0] != "max" {
+			quota, err1 := strconv.ParseFloat(fields[0], 64)
+			period, err2 := strconv.ParseFloat(fields[1], 64)
+			if err1 == nil && err2 == nil && period > 0 {
+				cpuCores = quota / period
+			}
+		}
+		if mem, err := strconv.ParseInt(readCgroupFile("/sys/fs/cgroup/memory.max"), 10, 64); err == nil {
+			memBytes = mem
+		}
+		return version, cpuCores, memBytes
+	}
+
+	quotaRaw := readCgroupFile("/sys/fs/cgroup/cpu/cpu.cfs_quota_us")
+	memRaw := readCgroupFile("/sys/fs/cgroup/memory/memory.limit_in_bytes")
+	if quotaRaw == "" && memRaw == "" {
+		return "", 0, 0
+	}
+	version = "v1"
+	quota, err1 := strconv.ParseFloat(quotaRaw, 64)
+	period, err2 := strconv.ParseFloat(readCgroupFile("/sys/fs/cgroup/cpu/cpu.cfs_period_us"), 64)
+	if err1 == nil && err2 == nil && quota > 0 && period > 0 {
+		cpuCores = quota / period
+	}
+	// v1 reports "no limit" as a huge page-aligned number
+	if mem, err := strconv.ParseInt(memRaw, 10, 64); err == nil && mem < 1<<62 {
+		memBytes = mem
+	}
+	return version, cpuCores, memBytes
+}
+
+func handleEnvInfo(ctx context.Context, req *mcp.CallToolRequest, args EnvInfoArgs) (*mcp.CallToolResult, EnvInfoOutput, error) {
+	version, cpuCores, memBytes := cgroupLimits()
+	_, dockerErr := os.Stat("/.dockerenv")
+
+	out := EnvInfoOutput{
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		GoVersion:        runtime.Version(),
+		NumCPU:           runtime.NumCPU(),
+		GOMAXPROCS:       runtime.GOMAXPROCS(0),
+		CgroupVersion:    version,
+		CPULimitCores:    cpuCores,
+		MemoryLimitBytes: memBytes,
+		InContainer:      dockerErr == nil || cpuCores > 0 || memBytes > 0,
+		GOGC:             os.Getenv("GOGC"),
+		GOMEMLIMIT:       os.Getenv("GOMEMLIMIT"),
+		GODEBUG:          os.Getenv("GODEBUG"),
+		ServerType:       "go",
+		SchemaVersion:    outputSchemaVersion,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		out.BuildSettings = make(map[string]string, len(info.Settings))
+		for _, setting := range info.Settings {
+			out.BuildSettings[setting.Key] = setting.Value
+		}
+	}
+
+	return nil, out, nil
+}
+
+var mathOperations = map[string]func(float64) float64{
+	"sin":  math.Sin,
+	"cos":  math.Cos,
+	"exp":  math.Exp,
+	"log":  math.Log,
+	"sqrt": math.Sqrt,
+}
+
+func handleMathBench(ctx context.Context, req *mcp.CallToolRequest, args MathBenchArgs) (*mcp.CallToolResult, MathBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 10000000 {
+		return nil, MathBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000000")
+	}
+	fn, ok := mathOperations[args.Operation]
+	if !ok {
+		return nil, MathBenchOutput{}, fmt.Errorf("operation deve ser sin, cos, exp, log ou sqrt")
+	}
+
+	// Inputs cycle through (0, 10] so exp stays finite and log/sqrt stay defined;
+	// the checksum keeps the compiler from discarding the calls
+	checksum := 0.0
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		x := float64(i%10000+1) * 0.001
+		checksum += fn(x)
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, MathBenchOutput{
+		Operation:     args.Operation,
+		Iterations:    args.Iterations,
+		Checksum:      checksum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		OpsPerSec:     float64(args.Iterations) / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// smallestFactor returns the smallest divisor of m in [from, sqrt(m)], or m
+// itself when there is none. With workers > 1 the range is split into
+// contiguous chunks scanned concurrently; a chunk gives up as soon as an
+// earlier chunk has found a divisor.
+func smallestFactor(ctx context.Context, m, from uint64, workers int) (uint64, error) {
+	limit := uint64(math.Sqrt(float64(m)))
+	for limit*limit > m {
+		limit--
+	}
+	for (limit+1)*(limit+1) <= m {
+		limit++
+	}
+	if from > limit {
+		return m, nil
+	}
+
+	span := limit - from + 1
+	if uint64(workers) > span {
+		workers = int(span)
+	}
+	chunk := (span + uint64(workers) - 1) / uint64(workers)
+
+	var best atomic.Uint64
+	best.Store(m)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		lo := from + uint64(w)*chunk
+		hi := min(lo+chunk-1, limit)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := lo; d <= hi; d++ {
+				if d&0xFFFFF == 0 && (ctx.Err() != nil || best.Load() < lo) {
+					return
+				}
+				if m%d == 0 {
+					for cur := best.Load(); d < cur && !best.CompareAndSwap(cur, d); cur = best.Load() {
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return best.Load(), nil
+}
+
+const maxExactJSONInt = 1 << 53
+
+func handleFactorize(ctx context.Context, req *mcp.CallToolRequest, args FactorizeArgs) (*mcp.CallToolResult, FactorizeOutput, error) {
+	// Arguments arrive as JSON numbers, which are only exact up to 2^53
+	if args.N == 0 || args.N > maxExactJSONInt || args.N < -maxExactJSONInt {
+		return nil, FactorizeOutput{}, fmt.Errorf("n deve ser diferente de 0 e ter módulo até 2^53")
+	}
+
+	workers := 1
+	if args.Parallel {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	startTime := time.Now()
+	// Negative inputs are reported as -1 followed by the factors of |n|
+	factors := []int64{}
+	m := uint64(args.N)
+	if args.N < 0 {
+		factors = append(factors, -1)
+		m = uint64(-args.N)
+	}
+	for p := uint64(2); m > 1; {
+		f, err := smallestFactor(ctx, m, p, workers)
+		if err != nil {
+			return nil, FactorizeOutput{}, fmt.Errorf("fatoração cancelada: %w", err)
+		}
+		factors = append(factors, int64(f))
+		m /= f
+		p = f
+	}
+
+	return nil, FactorizeOutput{
+		N:             args.N,
+		Factors:       factors,
+		Parallel:      args.Parallel,
+		Workers:       workers,
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// boxingSlots is the ring both boxing_bench loops write through. Slices are
+// heap-backed, so the interface{} stores can't be optimised onto the stack.
+const boxingSlots = 1024
+
+// measureAllocs runs fn and returns its duration and the number of heap
+// allocations the runtime recorded meanwhile.
+func measureAllocs(fn func()) (time.Duration, uint64) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	startTime := time.Now()
+	fn()
+	elapsed := time.Since(startTime)
+	runtime.ReadMemStats(&after)
+	return elapsed, after.Mallocs - before.Mallocs
+}
+
+func handleBoxingBench(ctx context.Context, req *mcp.CallToolRequest, args BoxingBenchArgs) (*mcp.CallToolResult, BoxingBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 10000000 {
+		return nil, BoxingBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000000")
+	}
+
+	// Values start above 255 because Go boxes small integers without allocating
+	var boxedSum, typedSum int64
+	boxed := make([]interface{}, boxingSlots)
+	for j := range boxed {
+		boxed[j] = 0 // slots read before their first write must still hold an int
+	}
+	boxedElapsed, boxedAllocs := measureAllocs(func() {
+		for i := 0; i < args.Iterations; i++ {
+			boxed[i%boxingSlots] = i + 256
+			boxedSum += int64(boxed[(i*7)%boxingSlots].(int))
+		}
+	})
+	typed := make([]int, boxingSlots)
+	typedElapsed, typedAllocs := measureAllocs(func() {
+		for i := 0; i < args.Iterations; i++ {
+			typed[i%boxingSlots] = i + 256
+			typedSum += int64(typed[(i*7)%boxingSlots])
+		}
+	})
+	if boxedSum != typedSum {
+		return nil, BoxingBenchOutput{}, fmt.Errorf("checksums divergentes: boxed %d, typed %d", boxedSum, typedSum)
+	}
+
+	boxedMs := float64(boxedElapsed.Nanoseconds()) / 1e6
+	typedMs := float64(typedElapsed.Nanoseconds()) / 1e6
+	return nil, BoxingBenchOutput{
+		Iterations:    args.Iterations,
+		BoxedMs:       boxedMs,
+		TypedMs:       typedMs,
+		DiffMs:        boxedMs - typedMs,
+		BoxedNsPerOp:  float64(boxedElapsed.Nanoseconds()) / float64(args.Iterations),
+		TypedNsPerOp:  float64(typedElapsed.Nanoseconds()) / float64(args.Iterations),
+		BoxedAllocs:   boxedAllocs,
+		TypedAllocs:   typedAllocs,
+		Checksum:      typedSum,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// copyBlock is a 1 KiB struct; copy_bench builds size of them and hands each
+// one to a function by value or by pointer.
+type copyBlock struct {
+	data [128]int64
+}
+
+//go:noinline
+func sumBlockValue(b copyBlock) int64 {
+	return b.data[0] + b.data[len(b.data)-1]
+}
+
+//go:noinline
+func sumBlockPointer(b *copyBlock) int64 {
+	return b.data[0] + b.data[len(b.data)-1]
+}
+
+func handleCopyBench(ctx context.Context, req *mcp.CallToolRequest, args CopyBenchArgs) (*mcp.CallToolResult, CopyBenchOutput, error) {
+	if args.Size < 1 || args.Size > 10000 {
+		return nil, CopyBenchOutput{}, fmt.Errorf("size deve estar entre 1 e 10000")
+	}
+	if args.Iterations < 1 || args.Iterations > 100000 {
+		return nil, CopyBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000")
+	}
+	if args.Size*args.Iterations > 10000000 {
+		return nil, CopyBenchOutput{}, fmt.Errorf("size * iterations deve ser no máximo 10000000")
+	}
+	if args.Mode != "value" && args.Mode != "pointer" {
+		return nil, CopyBenchOutput{}, fmt.Errorf("mode deve ser value ou pointer")
+	}
+
+	blocks := make([]copyBlock, args.Size)
+	for i := range blocks {
+		for j := range blocks[i].data {
+			blocks[i].data[j] = int64(i + j)
+		}
+	}
+
+	var checksum int64
+	startTime := time.Now()
+	for it := 0; it < args.Iterations; it++ {
+		if args.Mode == "value" {
+			for i := range blocks {
+				checksum += sumBlockValue(blocks[i])
+			}
+		} else {
+			for i := range blocks {
+				checksum += sumBlockPointer(&blocks[i])
+			}
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, CopyBenchOutput{
+		Mode:          args.Mode,
+		Size:          args.Size,
+		Iterations:    args.Iterations,
+		BytesPerIter:  args.Size * int(unsafe.Sizeof(copyBlock{})),
+		Checksum:      checksum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerIter:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleHistogram(ctx context.Context, req *mcp.CallToolRequest, args HistogramArgs) (*mcp.CallToolResult, HistogramOutput, error) {
+	if args.Buckets < 1 || args.Buckets > 10000 {
+		return nil, HistogramOutput{}, fmt.Errorf("buckets deve estar entre 1 e 10000")
+	}
+	if len(args.Values) > 1000000 {
+		return nil, HistogramOutput{}, fmt.Errorf("values deve ter no máximo 1000000 elementos")
+	}
+
+	startTime := time.Now()
+	counts := make([]int, args.Buckets)
+	// No values means no range to split; report empty buckets rather than
+	// inventing boundaries
+	if len(args.Values) == 0 {
+		return nil, HistogramOutput{
+			Boundaries:    []float64{},
+			Counts:        counts,
+			ElapsedMs:     elapsedMs(startTime),
+			ServerType:    "go",
+			SchemaVersion: outputSchemaVersion,
+		}, nil
+	}
+
+	for _, v := range args.Values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, HistogramOutput{}, fmt.Errorf("values deve conter apenas números finitos")
+		}
+	}
+	minV, maxV := args.Values[0], args.Values[0]
+	for _, v := range args.Values[1:] {
+		minV = min(minV, v)
+		maxV = max(maxV, v)
+	}
+	// A single distinct value gets a unit-wide range centred on it. At large
+	// magnitudes the widening is lost to rounding, and very wide ranges
+	// overflow; both would leave no usable bucket width.
+	lo, hi := minV, maxV
+	if lo == hi {
+		lo, hi = lo-0.5, hi+0.5
+	}
+	span := hi - lo
+	if span == 0 || math.IsInf(span, 0) {
+		return nil, HistogramOutput{}, fmt.Errorf("intervalo de values não representável (max-min deve ser finito e maior que zero)")
+	}
+	width := span / float64(args.Buckets)
+
+	boundaries := make([]float64, args.Buckets+1)
+	for i := range boundaries {
+		boundaries[i] = lo + width*float64(i)
+	}
+	boundaries[args.Buckets] = hi
+
+	// Buckets are [lo, hi) except the last, which also includes the maximum.
+	// The clamp guards against rounding at either edge.
+	for _, v := range args.Values {
+		i := max(0, min(int((v-lo)/width), args.Buckets-1))
+		counts[i]++
+	}
+
+	return nil, HistogramOutput{
+		Count:         len(args.Values),
+		Min:           minV,
+		Max:           maxV,
+		Boundaries:    boundaries,
+		Counts:        counts,
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleAppendBench(ctx context.Context, req *mcp.CallToolRequest, args AppendBenchArgs) (*mcp.CallToolResult, AppendBenchOutput, error) {
+	if args.Count < 1 || args.Count > 10000000 {
+		return nil, AppendBenchOutput{}, fmt.Errorf("count deve estar entre 1 e 10000000")
+	}
+
+	// Capacity is checked after every append; a change means the runtime
+	// moved the backing array. The check costs the same in both modes.
+	var values []int64
+	startTime := time.Now()
+	if args.Preallocate {
+		values = make([]int64, 0, args.Count)
+	}
+	reallocations, lastCap := 0, cap(values)
+	for i := 0; i < args.Count; i++ {
+		values = append(values, int64(i))
+		if c := cap(values); c != lastCap {
+			reallocations++
+			lastCap = c
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, AppendBenchOutput{
+		Count:         len(values),
+		Preallocate:   args.Preallocate,
+		Reallocations: reallocations,
+		FinalCap:      cap(values),
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerAppend:   float64(elapsed.Nanoseconds()) / float64(args.Count),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// pipelineWords is the size of the buffer handed from stage to stage
+const pipelineWords = 1024
+
+// runPipelineStage mixes data in place with an xorshift-multiply round
+// until work has elapsed, checking ctx between rounds. It returns the
+// number of rounds done and a checksum of the result.
+func runPipelineStage(ctx context.Context, data []uint64, stage int, work time.Duration) (int, uint64, error) {
+	deadline := time.Now().Add(work)
+	rounds := 0
+	for {
+		for i := range data {
+			x := data[i] ^ uint64(stage)
+			x ^= x << 13
+			x ^= x >> 7
+			x ^= x << 17
+			data[i] = x * 0x9e3779b97f4a7c15
+		}
+		rounds++
+		if !time.Now().Before(deadline) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return rounds, 0, err
+		}
+	}
+	var sum uint64
+	for _, x := range data {
+		sum += x
+	}
+	return rounds, sum, nil
+}
+
+func handlePipeline(ctx context.Context, req *mcp.CallToolRequest, args PipelineArgs) (*mcp.CallToolResult, PipelineOutput, error) {
+	if args.Stages < 1 || args.Stages > 100 {
+		return nil, PipelineOutput{}, fmt.Errorf("stages deve estar entre 1 e 100")
+	}
+	if args.WorkMs < 0 || args.WorkMs > 10000 {
+		return nil, PipelineOutput{}, fmt.Errorf("work_ms deve estar entre 0 e 10000")
+	}
+	if args.Stages*args.WorkMs > 60000 {
+		return nil, PipelineOutput{}, fmt.Errorf("stages * work_ms deve ser no máximo 60000")
+	}
+
+	data := make([]uint64, pipelineWords)
+	for i := range data {
+		data[i] = uint64(i + 1)
+	}
+
+	stages := make([]PipelineStage, 0, args.Stages)
+	var checksum uint64
+	startTime := time.Now()
+	for s := 1; s <= args.Stages; s++ {
+		stageStart := time.Now()
+		rounds, sum, err := runPipelineStage(ctx, data, s, time.Duration(args.WorkMs)*time.Millisecond)
+		if err != nil {
+			return nil, PipelineOutput{}, fmt.Errorf("pipeline cancelado no estágio %d: %w", s, err)
+		}
+		stages = append(stages, PipelineStage{
+			Stage:     s,
+			ElapsedMs: elapsedMs(stageStart),
+			Rounds:    rounds,
+			Checksum:  sum,
+		})
+		checksum = sum
+	}
+
+	return nil, PipelineOutput{
+		Stages:        stages,
+		TotalMs:       elapsedMs(startTime),
+		Checksum:      checksum,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// marshalMaxNodes bounds the tree marshal_bench builds; breadth^depth grows fast
+const marshalMaxNodes = 200000
+
+// buildNestedMap returns a map with breadth children per level down to depth,
+// whose leaves mix strings, numbers and booleans like a typical payload
+func buildNestedMap(depth, breadth int) map[string]interface{} {
+	m := make(map[string]interface{}, breadth)
+	for i := 0; i < breadth; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if depth > 1 {
+			m[key] = buildNestedMap(depth-1, breadth)
+			continue
+		}
+		switch i % 3 {
+		case 0:
+			m[key] = fmt.Sprintf("value-%d", i)
+		case 1:
+			m[key] = float64(i) * 1.5
+		default:
+			m[key] = i%2 == 0
+		}
+	}
+	return m
+}
+
+func handleMarshalBench(ctx context.Context, req *mcp.CallToolRequest, args MarshalBenchArgs) (*mcp.CallToolResult, MarshalBenchOutput, error) {
+	if args.Depth < 1 || args.Depth > 20 {
+		return nil, MarshalBenchOutput{}, fmt.Errorf("depth deve estar entre 1 e 20")
+	}
+	if args.Breadth < 1 || args.Breadth > 1000 {
+		return nil, MarshalBenchOutput{}, fmt.Errorf("breadth deve estar entre 1 e 1000")
+	}
+	if args.Iterations < 1 || args.Iterations > 10000 {
+		return nil, MarshalBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000")
+	}
+	// Count nodes level by level, stopping as soon as the cap is exceeded
+	nodes, level := 0, 1
+	for d := 0; d < args.Depth; d++ {
+		level *= args.Breadth
+		nodes += level
+		if nodes > marshalMaxNodes {
+			return nil, MarshalBenchOutput{}, fmt.Errorf("breadth^depth excede o limite de %d nós", marshalMaxNodes)
+		}
+	}
+	if nodes*args.Iterations > 20000000 {
+		return nil, MarshalBenchOutput{}, fmt.Errorf("nós * iterations deve ser no máximo 20000000")
+	}
+
+	tree := buildNestedMap(args.Depth, args.Breadth)
+	size := 0
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, MarshalBenchOutput{}, fmt.Errorf("benchmark cancelado: %w", err)
+		}
+		data, err := json.Marshal(tree)
+		if err != nil {
+			return nil, MarshalBenchOutput{}, fmt.Errorf("falha ao serializar: %v", err)
+		}
+		size = len(data)
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, MarshalBenchOutput{
+		Depth:         args.Depth,
+		Breadth:       args.Breadth,
+		Nodes:         nodes,
+		Iterations:    args.Iterations,
+		Bytes:         size,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		MBPerSec:      float64(size) * float64(args.Iterations) / 1e6 / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// contextBenchKey is the value key used by context_bench; each level stores its depth
+type contextBenchKey struct{ level int }
+
+func handleContextBench(ctx context.Context, req *mcp.CallToolRequest, args ContextBenchArgs) (*mcp.CallToolResult, ContextBenchOutput, error) {
+	if args.Depth < 1 || args.Depth > 1000 {
+		return nil, ContextBenchOutput{}, fmt.Errorf("depth deve estar entre 1 e 1000")
+	}
+	if args.Iterations < 1 || args.Iterations > 100000 {
+		return nil, ContextBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000")
+	}
+	if args.Depth*args.Iterations > 10000000 {
+		return nil, ContextBenchOutput{}, fmt.Errorf("depth * iterations deve ser no máximo 10000000")
+	}
+
+	// Each iteration alternates WithValue and WithCancel down to depth, then
+	// looks up the shallowest value, which walks the whole chain
+	var lookups int64
+	startTime := time.Now()
+	for it := 0; it < args.Iterations; it++ {
+		if err := ctx.Err(); err != nil {
+			return nil, ContextBenchOutput{}, fmt.Errorf("benchmark cancelado: %w", err)
+		}
+		cur := context.Background()
+		cancels := make([]context.CancelFunc, 0, args.Depth/2+1)
+		for level := 0; level < args.Depth; level++ {
+			if level%2 == 0 {
+				cur = context.WithValue(cur, contextBenchKey{level}, level)
+			} else {
+				var cancel context.CancelFunc
+				cur, cancel = context.WithCancel(cur)
+				cancels = append(cancels, cancel)
+			}
+		}
+		if v, ok := cur.Value(contextBenchKey{0}).(int); ok && v == 0 {
+			lookups++
+		}
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, ContextBenchOutput{
+		Depth:         args.Depth,
+		Iterations:    args.Iterations,
+		Lookups:       lookups,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerIter:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// deferWork and inlineWork do the same lock/add/unlock; the only difference
+// is how the unlock runs. noinline keeps each call a real function frame.
+//
+//go:noinline
+func deferWork(mu *sync.Mutex, acc *int64, i int) {
+	mu.Lock()
+	defer mu.Unlock()
+	*acc += int64(i)
+}
+
+//go:noinline
+func inlineWork(mu *sync.Mutex, acc *int64, i int) {
+	mu.Lock()
+	*acc += int64(i)
+	mu.Unlock()
+}
+
+func handleDeferBench(ctx context.Context, req *mcp.CallToolRequest, args DeferBenchArgs) (*mcp.CallToolResult, DeferBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 100000000 {
+		return nil, DeferBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000000")
+	}
+
+	var mu sync.Mutex
+	var checksum int64
+	work := inlineWork
+	if args.UseDefer {
+		work = deferWork
+	}
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		work(&mu, &checksum, i)
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, DeferBenchOutput{
+		Iterations:    args.Iterations,
+		UseDefer:      args.UseDefer,
+		Checksum:      checksum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerCall:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleMemoryAccessBench(ctx context.Context, req *mcp.CallToolRequest, args MemoryAccessBenchArgs) (*mcp.CallToolResult, MemoryAccessBenchOutput, error) {
+	if args.SizeMB < 1 || args.SizeMB > 512 {
+		return nil, MemoryAccessBenchOutput{}, fmt.Errorf("size_mb deve estar entre 1 e 512")
+	}
+	if args.Pattern != "sequential" && args.Pattern != "random" {
+		return nil, MemoryAccessBenchOutput{}, fmt.Errorf("pattern deve ser sequential ou random")
+	}
+
+	n := args.SizeMB << 20 / 8
+	data := make([]int64, n)
+	for i := range data {
+		data[i] = int64(i)
+	}
+	// Both patterns visit every element exactly once. The random order comes
+	// from a full-period LCG modulo the next power of two, skipping indices
+	// past the end; it is computed inline so the permutation doesn't need a
+	// second array competing for the cache.
+	var sum int64
+	startTime := time.Now()
+	if args.Pattern == "sequential" {
+		for i := 0; i < n; i++ {
+			sum += data[i]
+		}
+	} else {
+		mask := uint64(1)<<bits.Len(uint(n-1)) - 1
+		idx := uint64(0)
+		for visited := 0; visited < n; {
+			idx = (idx*6364136223846793005 + 1442695040888963407) & mask
+			if idx < uint64(n) {
+				sum += data[idx]
+				visited++
+			}
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, MemoryAccessBenchOutput{
+		SizeMB:        args.SizeMB,
+		Pattern:       args.Pattern,
+		Elements:      n,
+		Sum:           sum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerElement:  float64(elapsed.Nanoseconds()) / float64(n),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleMapChurn(ctx context.Context, req *mcp.CallToolRequest, args MapChurnArgs) (*mcp.CallToolResult, MapChurnOutput, error) {
+	if args.Size < 0 || args.Size > 1000000 {
+		return nil, MapChurnOutput{}, fmt.Errorf("size deve estar entre 0 e 1000000")
+	}
+	if args.Operations < 1 || args.Operations > 10000000 {
+		return nil, MapChurnOutput{}, fmt.Errorf("operations deve estar entre 1 e 10000000")
+	}
+
+	rng := rand.New(rand.NewSource(args.Seed))
+	out := MapChurnOutput{Size: args.Size, Operations: args.Operations, Seed: args.Seed, ServerType: "go", SchemaVersion: outputSchemaVersion}
+	// Keys are drawn from twice the initial size, so inserts and deletes both
+	// hit often and the map keeps growing and shrinking around its start size
+	keySpace := max(2*args.Size, 1)
+	startTime := time.Now()
+	m := make(map[int]int, args.Size)
+	for i := 0; i < args.Size; i++ {
+		m[i*2] = i
+	}
+	for i := 0; i < args.Operations; i++ {
+		key := rng.Intn(keySpace)
+		if rng.Intn(2) == 0 {
+			m[key] = i
+			out.Inserts++
+		} else {
+			delete(m, key)
+			out.Deletes++
+		}
+	}
+	// Summing the keys is order-independent, so the result stays deterministic
+	// despite Go's randomised map iteration
+	for key := range m {
+		out.KeySum += int64(key)
+	}
+	out.ElapsedMs = elapsedMs(startTime)
+	out.FinalSize = len(m)
+	return nil, out, nil
+}
+
+// processCPUTime returns the user+system CPU time consumed by this process
+func processCPUTime() time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// cpuSample is the previous load_info reading; CPU utilisation is the CPU
+// time used between two readings over the wall time between them
+var cpuSample = struct {
+	mu  sync.Mutex
+	at  time.Time
+	cpu time.Duration
+}{at: time.Now()}
+
+func handleLoadInfo(ctx context.Context, req *mcp.CallToolRequest, args LoadInfoArgs) (*mcp.CallToolResult, LoadInfoOutput, error) {
+	now, cpu := time.Now(), processCPUTime()
+	cpuSample.mu.Lock()
+	window := now.Sub(cpuSample.at)
+	used := cpu - cpuSample.cpu
+	cpuSample.at, cpuSample.cpu = now, cpu
+	cpuSample.mu.Unlock()
+
+	// Percent of the whole machine, so 100 means every GOMAXPROCS slot busy
+	cpuPercent := 0.0
+	if window > 0 {
+		cpuPercent = float64(used) / float64(window) / float64(runtime.GOMAXPROCS(0)) * 100
+	}
+	avgMs, samples := metrics.recentAvgMs()
+
+	return nil, LoadInfoOutput{
+		// Includes the request carrying this call
+		InFlightRequests: inFlight.Load(),
+		RecentAvgMs:      avgMs,
+		RecentSamples:    samples,
+		CPUPercent:       cpuPercent,
+		CPUWindowMs:      float64(window.Nanoseconds()) / 1e6,
+		GOMAXPROCS:       runtime.GOMAXPROCS(0),
+		Goroutines:       runtime.NumGoroutine(),
+		MemoryDegraded:   memoryDegraded.Load(),
+		ServerType:       "go",
+		SchemaVersion:    outputSchemaVersion,
+	}, nil
+}
+
+var errBenchBase = errors.New("falha base")
+
+// errorBenchModes build one error per call; the loop keeps each result in a
+// heap-backed sink so none of them can be optimised away
+var errorBenchModes = map[string]func(i int) error{
+	"fmt":        func(i int) error { return fmt.Errorf("falha na operação %d", i) },
+	"errors-new": func(i int) error { return errors.New("falha na operação") },
+	"wrapped":    func(i int) error { return fmt.Errorf("operação %d: %w", i, errBenchBase) },
+}
+
+func handleErrorBench(ctx context.Context, req *mcp.CallToolRequest, args ErrorBenchArgs) (*mcp.CallToolResult, ErrorBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 10000000 {
+		return nil, ErrorBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000000")
+	}
+	build, ok := errorBenchModes[args.Mode]
+	if !ok {
+		return nil, ErrorBenchOutput{}, fmt.Errorf("mode deve ser fmt, errors-new ou wrapped")
+	}
+
+	// matched counts errors.Is hits, which only wrapped errors produce
+	sink := make([]error, 64)
+	matched := 0
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		err := build(i)
+		sink[i%len(sink)] = err
+		if errors.Is(err, errBenchBase) {
+			matched++
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, ErrorBenchOutput{
+		Mode:          args.Mode,
+		Iterations:    args.Iterations,
+		Matched:       matched,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerError:    float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleFetchParallel(ctx context.Context, req *mcp.CallToolRequest, args FetchParallelArgs) (*mcp.CallToolResult, FetchParallelOutput, error) {
+	if len(args.Endpoints) < 1 || len(args.Endpoints) > 100 {
+		return nil, FetchParallelOutput{}, fmt.Errorf("endpoints deve ter entre 1 e 100 URLs")
+	}
+	if args.Concurrency < 1 || args.Concurrency > 32 {
+		return nil, FetchParallelOutput{}, fmt.Errorf("concurrency deve estar entre 1 e 32")
+	}
+	for i, endpoint := range args.Endpoints {
+		if n := len(endpoint); n > cfg.FetchMaxURLLen {
+			return nil, FetchParallelOutput{}, inputLimitError(fmt.Sprintf("endpoints[%d]", i), "FETCH_MAX_URL_LEN", cfg.FetchMaxURLLen, n)
+		}
+	}
+
+	// Workers pull indices from jobs and write only their own result slot
+	results := make([]FetchParallelResult, len(args.Endpoints))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for w := 0; w < min(args.Concurrency, len(args.Endpoints)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = fetchOne(ctx, args.Endpoints[i])
+			}
+		}()
+	}
+	for i := range args.Endpoints {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, FetchParallelOutput{}, fmt.Errorf("fetch_parallel cancelado: %w", err)
+	}
+
+	out := FetchParallelOutput{
+		Results:       results,
+		Concurrency:   args.Concurrency,
+		WallTimeMs:    elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			out.Failed++
+		} else {
+			out.Succeeded++
+		}
+	}
+	return nil, out, nil
+}
+
+// fetchOne GETs endpoint, discarding the body, behind the same circuit
+// breaker and redirect limit as fetch_external_data
+func fetchOne(ctx context.Context, endpoint string) FetchParallelResult {
+	result := FetchParallelResult{URL: endpoint}
+	if err := validateFetchURL(endpoint); err != nil {
+		result.Error, result.ErrorKind = err.Error(), "invalid_url"
+		return result
+	}
+	startTime := time.Now()
+	u, _ := url.Parse(endpoint)
+	if !circuitAllow(u.Host) {
+		result.ResponseTimeMs = elapsedMs(startTime)
+		result.CircuitOpen = true
+		result.Error = fmt.Sprintf("circuito aberto para %s", u.Host)
+		result.ErrorKind = "circuit_open"
+		return result
+	}
+	var rd fetchRedirects
+	client := redirectLimitedClient(true, defaultMaxRedirects, &rd)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = client.Do(httpReq); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			result.StatusCode = resp.StatusCode
+		}
+	}
+	result.ResponseTimeMs = elapsedMs(startTime)
+	result.Redirects = rd.count
+	// A cancelled call says nothing about the host either
+	blocked := errors.Is(err, errFetchBlocked)
+	if !rd.exceeded && !blocked && ctx.Err() == nil {
+		circuitRecord(u.Host, err != nil || result.StatusCode >= 500)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		switch {
+		case rd.exceeded:
+			result.ErrorKind = "too_many_redirects"
+		case blocked:
+			result.ErrorKind = "blocked_address"
+		}
+	}
+	return result
+}
+
+// reflectRecord is the struct reflect_bench reads, one int field at a time
+type reflectRecord struct {
+	ID     int64
+	Count  int64
+	Total  int64
+	Offset int64
+}
+
+func handleReflectBench(ctx context.Context, req *mcp.CallToolRequest, args ReflectBenchArgs) (*mcp.CallToolResult, ReflectBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 10000000 {
+		return nil, ReflectBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000000")
+	}
+	if args.Mode != "direct" && args.Mode != "reflect" {
+		return nil, ReflectBenchOutput{}, fmt.Errorf("mode deve ser direct ou reflect")
+	}
+
+	// Each iteration updates ID so every pass reads a fresh value; both modes
+	// sum all four fields
+	rec := &reflectRecord{Count: 2, Total: 3, Offset: 4}
+	var checksum int64
+	startTime := time.Now()
+	if args.Mode == "direct" {
+		for i := 0; i < args.Iterations; i++ {
+			rec.ID = int64(i)
+			checksum += rec.ID + rec.Count + rec.Total + rec.Offset
+		}
+	} else {
+		v := reflect.ValueOf(rec).Elem()
+		for i := 0; i < args.Iterations; i++ {
+			rec.ID = int64(i)
+			for f := 0; f < v.NumField(); f++ {
+				checksum += v.Field(f).Int()
+			}
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, ReflectBenchOutput{
+		Mode:          args.Mode,
+		Iterations:    args.Iterations,
+		Checksum:      checksum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerIter:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// csvCell returns deterministic cell content; every few cells need quoting
+// (embedded comma, quote or newline) so both tools exercise the quoting path
+func csvCell(row, col int) string {
+	switch (row + col) % 7 {
+	case 3:
+		return fmt.Sprintf("valor %d, com vírgula", row*col)
+	case 5:
+		return fmt.Sprintf("diz \"%d\"", row)
+	case 6:
+		return fmt.Sprintf("linha\nquebrada %d", col)
+	}
+	return strconv.Itoa(row*1000 + col)
+}
+
+func handleGenerateCSV(ctx context.Context, req *mcp.CallToolRequest, args GenerateCSVArgs) (*mcp.CallToolResult, GenerateCSVOutput, error) {
+	if args.Rows < 1 || args.Rows > 100000 {
+		return nil, GenerateCSVOutput{}, fmt.Errorf("rows deve estar entre 1 e 100000")
+	}
+	if args.Cols < 1 || args.Cols > 100 {
+		return nil, GenerateCSVOutput{}, fmt.Errorf("cols deve estar entre 1 e 100")
+	}
+	if args.Rows*args.Cols > 1000000 {
+		return nil, GenerateCSVOutput{}, fmt.Errorf("rows * cols deve ser no máximo 1000000")
+	}
+
+	startTime := time.Now()
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	record := make([]string, args.Cols)
+	for c := range record {
+		record[c] = fmt.Sprintf("col_%d", c)
+	}
+	w.Write(record)
+	for r := 0; r < args.Rows; r++ {
+		for c := range record {
+			record[c] = csvCell(r, c)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, GenerateCSVOutput{}, fmt.Errorf("falha ao gerar CSV: %v", err)
+	}
+
+	return nil, GenerateCSVOutput{
+		Data:          buf.String(),
+		Rows:          args.Rows,
+		Cols:          args.Cols,
+		Bytes:         buf.Len(),
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// parseCSVMaxErrors caps how many parse errors are reported individually
+const parseCSVMaxErrors = 100
+
+func handleParseCSV(ctx context.Context, req *mcp.CallToolRequest, args ParseCSVArgs) (*mcp.CallToolResult, ParseCSVOutput, error) {
+	if len(args.Data) > 16<<20 {
+		return nil, ParseCSVOutput{}, fmt.Errorf("data deve ter no máximo 16 MiB")
+	}
+
+	out := ParseCSVOutput{ServerType: "go", SchemaVersion: outputSchemaVersion}
+	startTime := time.Now()
+	r := csv.NewReader(strings.NewReader(args.Data))
+	// Rows with a different field count are reported, not fatal; the first
+	// record still fixes the expected width
+	r.FieldsPerRecord = 0
+	r.ReuseRecord = true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if !errors.As(err, &perr) {
+				return nil, ParseCSVOutput{}, fmt.Errorf("falha ao ler CSV: %v", err)
+			}
+			out.ErrorCount++
+			if len(out.Errors) < parseCSVMaxErrors {
+				out.Errors = append(out.Errors, CSVParseError{Line: perr.Line, Column: perr.Column, Message: perr.Err.Error()})
+			}
+			// Field count errors still return the record; quoting errors don't
+			if !errors.Is(err, csv.ErrFieldCount) {
+				continue
+			}
+		}
+		if out.Rows == 0 {
+			out.Columns = len(record)
+		}
+		out.Rows++
+		out.Fields += len(record)
+	}
+	out.ElapsedMs = elapsedMs(startTime)
+	return nil, out, nil
+}
+
+func handleClockBench(ctx context.Context, req *mcp.CallToolRequest, args ClockBenchArgs) (*mcp.CallToolResult, ClockBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 100000000 {
+		return nil, ClockBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000000")
+	}
+
+	// Summing the nanosecond component keeps every time.Now() call live
+	var sum int64
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		sum += int64(time.Now().Nanosecond())
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, ClockBenchOutput{
+		Iterations:    args.Iterations,
+		NanosSum:      sum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerCall:     float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleRNGBench(ctx context.Context, req *mcp.CallToolRequest, args RNGBenchArgs) (*mcp.CallToolResult, RNGBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 100000000 {
+		return nil, RNGBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000000")
+	}
+	if args.Concurrency < 1 || args.Concurrency > 256 {
+		return nil, RNGBenchOutput{}, fmt.Errorf("concurrency deve estar entre 1 e 256")
+	}
+	if args.Mode != "shared" && args.Mode != "per-goroutine" {
+		return nil, RNGBenchOutput{}, fmt.Errorf("mode deve ser shared ou per-goroutine")
+	}
+
+	// iterations is the total, split evenly with the remainder going to the
+	// first goroutines. Sources are seeded up front so setup isn't timed.
+	var mu sync.Mutex
+	shared := rand.New(rand.NewSource(1))
+	sources := make([]*rand.Rand, args.Concurrency)
+	for g := range sources {
+		sources[g] = rand.New(rand.NewSource(int64(g + 1)))
+	}
+	var checksum atomic.Int64
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for g := 0; g < args.Concurrency; g++ {
+		n := args.Iterations / args.Concurrency
+		if g < args.Iterations%args.Concurrency {
+			n++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var sum int64
+			if args.Mode == "shared" {
+				for i := 0; i < n; i++ {
+					mu.Lock()
+					sum += shared.Int63() & 0xFFFF
+					mu.Unlock()
+				}
+			} else {
+				rng := sources[g]
+				for i := 0; i < n; i++ {
+					sum += rng.Int63() & 0xFFFF
+				}
+			}
+			checksum.Add(sum)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(startTime)
+
+	return nil, RNGBenchOutput{
+		Mode:          args.Mode,
+		Iterations:    args.Iterations,
+		Concurrency:   args.Concurrency,
+		Checksum:      checksum.Load(),
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerNumber:   float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		OpsPerSec:     float64(args.Iterations) / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// mandelbrotMaxImagePixels bounds include_image responses
+const mandelbrotMaxImagePixels = 65536
+
+// mandelbrotRow writes the escape iteration of each pixel in row y, over the
+// region [-2, 1] x [-1.5, 1.5]. Points that never escape get maxIter.
+func mandelbrotRow(out []int, y, width, height, maxIter int) {
+	ci := 1.5 - 3*float64(y)/float64(height)
+	for x := 0; x < width; x++ {
+		cr := -2 + 3*float64(x)/float64(width)
+		zr, zi := 0.0, 0.0
+		i := 0
+		for ; i < maxIter && zr*zr+zi*zi <= 4; i++ {
+			zr, zi = zr*zr-zi*zi+cr, 2*zr*zi+ci
+		}
+		out[x] = i
+	}
+}
+
+func handleMandelbrot(ctx context.Context, req *mcp.CallToolRequest, args MandelbrotArgs) (*mcp.CallToolResult, MandelbrotOutput, error) {
+	if args.Width < 1 || args.Width > 4096 || args.Height < 1 || args.Height > 4096 {
+		return nil, MandelbrotOutput{}, fmt.Errorf("width e height devem estar entre 1 e 4096")
+	}
+	if args.MaxIter < 1 || args.MaxIter > 100000 {
+		return nil, MandelbrotOutput{}, fmt.Errorf("max_iter deve estar entre 1 e 100000")
+	}
+	if args.Width*args.Height*args.MaxIter > 2000000000 {
+		return nil, MandelbrotOutput{}, fmt.Errorf("width * height * max_iter deve ser no máximo 2000000000")
+	}
+	if args.IncludeImage && args.Width*args.Height > mandelbrotMaxImagePixels {
+		return nil, MandelbrotOutput{}, fmt.Errorf("include_image exige width * height <= %d", mandelbrotMaxImagePixels)
+	}
+
+	workers := 1
+	if args.Parallel {
+		workers = min(runtime.GOMAXPROCS(0), args.Height)
+	}
+
+	// Workers claim whole rows from a shared counter and stop early on cancel
+	image := make([]int, args.Width*args.Height)
+	var nextRow atomic.Int64
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				y := int(nextRow.Add(1) - 1)
+				if y >= args.Height {
+					return
+				}
+				mandelbrotRow(image[y*args.Width:(y+1)*args.Width], y, args.Width, args.Height, args.MaxIter)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, MandelbrotOutput{}, fmt.Errorf("mandelbrot cancelado: %w", err)
+	}
+	elapsed := elapsedMs(startTime)
+
+	out := MandelbrotOutput{
+		Width:         args.Width,
+		Height:        args.Height,
+		MaxIter:       args.MaxIter,
+		Workers:       workers,
+		ElapsedMs:     elapsed,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}
+	for _, it := range image {
+		out.Checksum += int64(it)
+		if it < args.MaxIter {
+			out.Escaped++
+		}
+	}
+	if args.IncludeImage {
+		out.Image = image
+	}
+	return nil, out, nil
+}
+
+// ioWriteChunk is the size of each Write call in io_write_bench; small writes
+// are what buffering is meant to amortise
+const ioWriteChunk = 64
+
+func handleIOWriteBench(ctx context.Context, req *mcp.CallToolRequest, args IOWriteBenchArgs) (*mcp.CallToolResult, IOWriteBenchOutput, error) {
+	if args.Bytes < 1 || args.Bytes > 64<<20 {
+		return nil, IOWriteBenchOutput{}, fmt.Errorf("bytes deve estar entre 1 e %d (64 MiB)", 64<<20)
+	}
+
+	var dst io.Writer = io.Discard
+	target := "discard"
+	if args.ToFile {
+		f, err := os.CreateTemp("", "io_write_bench-*")
+		if err != nil {
+			return nil, IOWriteBenchOutput{}, fmt.Errorf("falha ao criar arquivo temporário: %v", err)
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		dst, target = f, "file"
+	}
+
+	chunk := bytes.Repeat([]byte("x"), ioWriteChunk)
+	writes := 0
+	startTime := time.Now()
+	w := dst
+	var bw *bufio.Writer
+	if args.Buffered {
+		bw = bufio.NewWriter(dst)
+		w = bw
+	}
+	for written := 0; written < args.Bytes; writes++ {
+		n := min(ioWriteChunk, args.Bytes-written)
+		if _, err := w.Write(chunk[:n]); err != nil {
+			return nil, IOWriteBenchOutput{}, fmt.Errorf("falha na escrita: %v", err)
+		}
+		written += n
+	}
+	if bw != nil {
+		if err := bw.Flush(); err != nil {
+			return nil, IOWriteBenchOutput{}, fmt.Errorf("falha no flush: %v", err)
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, IOWriteBenchOutput{
+		Bytes:         args.Bytes,
+		Buffered:      args.Buffered,
+		Target:        target,
+		Writes:        writes,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		MBPerSec:      float64(args.Bytes) / 1e6 / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleCounterBench(ctx context.Context, req *mcp.CallToolRequest, args CounterBenchArgs) (*mcp.CallToolResult, CounterBenchOutput, error) {
+	if args.Goroutines < 1 || args.Goroutines > 1000 {
+		return nil, CounterBenchOutput{}, fmt.Errorf("goroutines deve estar entre 1 e 1000")
+	}
+	if args.Increments < 1 || args.Increments > 1000000 {
+		return nil, CounterBenchOutput{}, fmt.Errorf("increments deve estar entre 1 e 1000000")
+	}
+	if args.Goroutines*args.Increments > 50000000 {
+		return nil, CounterBenchOutput{}, fmt.Errorf("goroutines * increments deve ser no máximo 50000000")
+	}
+
+	var (
+		mu            sync.Mutex
+		counter       int64
+		atomicCounter atomic.Int64
+		incr          func()
+		finalize      func() int64
+	)
+	switch args.Strategy {
+	case "mutex":
+		incr = func() {
+			mu.Lock()
+			counter++
+			mu.Unlock()
+		}
+		finalize = func() int64 { return counter }
+	case "atomic":
+		incr = func() { atomicCounter.Add(1) }
+		finalize = atomicCounter.Load
+	case "channel":
+		// A single owner goroutine applies every increment sent on ch
+		ch := make(chan struct{}, 1024)
+		done := make(chan struct{})
+		go func() {
+			for range ch {
+				counter++
+			}
+			close(done)
+		}()
+		incr = func() { ch <- struct{}{} }
+		finalize = func() int64 {
+			close(ch)
+			<-done
+			return counter
+		}
+	default:
+		return nil, CounterBenchOutput{}, fmt.Errorf("strategy deve ser mutex, atomic ou channel")
+	}
+
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for g := 0; g < args.Goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < args.Increments; i++ {
+				incr()
+			}
+		}()
+	}
+	wg.Wait()
+	final := finalize()
+	elapsed := time.Since(startTime)
+
+	expected := int64(args.Goroutines) * int64(args.Increments)
+	return nil, CounterBenchOutput{
+		Strategy:      args.Strategy,
+		Goroutines:    args.Goroutines,
+		Increments:    args.Increments,
+		FinalCount:    final,
+		ExpectedCount: expected,
+		Correct:       final == expected,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		OpsPerSec:     float64(expected) / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// gobRegisterOnce registers the container types JSON decoding produces, which
+// gob must know before it can encode them inside interface{} values
+var gobRegisterOnce sync.Once
+
+// binaryCodecs encode and decode a JSON-shaped value in each supported format
+var binaryCodecs = map[string]struct {
+	encode func(v map[string]interface{}) ([]byte, error)
+	decode func(data []byte) error
+}{
+	"json": {
+		encode: func(v map[string]interface{}) ([]byte, error) { return json.Marshal(v) },
+		decode: func(data []byte) error {
+			var v map[string]interface{}
+			return json.Unmarshal(data, &v)
+		},
+	},
+	"gob": {
+		encode: func(v map[string]interface{}) ([]byte, error) {
+			var buf bytes.Buffer
+			err := gob.NewEncoder(&buf).Encode(v)
+			return buf.Bytes(), err
+		},
+		decode: func(data []byte) error {
+			var v map[string]interface{}
+			return gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+		},
+	},
+}
+
+func handleBinarySerialize(ctx context.Context, req *mcp.CallToolRequest, args BinarySerializeArgs) (*mcp.CallToolResult, BinarySerializeOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 100000 {
+		return nil, BinarySerializeOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000")
+	}
+	codec, ok := binaryCodecs[args.Format]
+	if !ok {
+		return nil, BinarySerializeOutput{}, fmt.Errorf("format deve ser gob ou json")
+	}
+	gobRegisterOnce.Do(func() {
+		gob.Register(map[string]interface{}{})
+		gob.Register([]interface{}{})
+	})
+
+	// One trial encode surfaces values the codec cannot represent as a clear
+	// error before any timing starts
+	encoded, err := codec.encode(args.Data)
+	if err != nil {
+		return nil, BinarySerializeOutput{}, fmt.Errorf("%s não consegue serializar data: %v", args.Format, err)
+	}
+
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		if encoded, err = codec.encode(args.Data); err != nil {
+			return nil, BinarySerializeOutput{}, fmt.Errorf("falha ao serializar: %v", err)
+		}
+	}
+	encodeElapsed := time.Since(startTime)
+
+	startTime = time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		if err := codec.decode(encoded); err != nil {
+			return nil, BinarySerializeOutput{}, fmt.Errorf("falha ao desserializar: %v", err)
+		}
+	}
+	decodeElapsed := time.Since(startTime)
+
+	return nil, BinarySerializeOutput{
+		Format:        args.Format,
+		Iterations:    args.Iterations,
+		Bytes:         len(encoded),
+		EncodeMs:      float64(encodeElapsed.Nanoseconds()) / 1e6,
+		DecodeMs:      float64(decodeElapsed.Nanoseconds()) / 1e6,
+		NsPerEncode:   float64(encodeElapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// sumFloat64s adds v with four independent accumulators. Go doesn't emit SIMD
+// for this loop, but breaking the single add dependency chain lets the CPU
+// overlap the additions, which is as close as plain Go gets.
+func sumFloat64s(v []float64) float64 {
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(v); i += 4 {
+		s0 += v[i]
+		s1 += v[i+1]
+		s2 += v[i+2]
+		s3 += v[i+3]
+	}
+	for ; i < len(v); i++ {
+		s0 += v[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+func handleVectorSum(ctx context.Context, req *mcp.CallToolRequest, args VectorSumArgs) (*mcp.CallToolResult, VectorSumOutput, error) {
+	if args.Size < 1 || args.Size > 16<<20 {
+		return nil, VectorSumOutput{}, fmt.Errorf("size deve estar entre 1 e %d", 16<<20)
+	}
+	if args.Iterations < 1 || args.Iterations > 10000 {
+		return nil, VectorSumOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000")
+	}
+	if int64(args.Size)*int64(args.Iterations) > 2e9 {
+		return nil, VectorSumOutput{}, fmt.Errorf("size * iterations deve ser no máximo 2000000000")
+	}
+
+	v := make([]float64, args.Size)
+	for i := range v {
+		v[i] = float64(i%1000) * 0.5
+	}
+	// Accumulating every pass into the returned sum keeps the loop live
+	var sum float64
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		sum += sumFloat64s(v)
+	}
+	elapsed := time.Since(startTime)
+
+	bytesRead := float64(args.Size) * 8 * float64(args.Iterations)
+	return nil, VectorSumOutput{
+		Size:          args.Size,
+		Iterations:    args.Iterations,
+		Sum:           sum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		GBPerSec:      bytesRead / elapsed.Seconds() / 1e9,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// treeNode links children as first-child/next-sibling indices into one
+// slice, so the tree costs a single allocation however it is shaped
+type treeNode struct {
+	value       int64
+	firstChild  int32
+	nextSibling int32
+}
+
+// buildRandomTree attaches every node after the root to a uniformly random
+// earlier node, giving a random recursive tree of logarithmic expected depth
+func buildRandomTree(n int) []treeNode {
+	rng := rand.New(rand.NewSource(42))
+	tree := make([]treeNode, n)
+	for i := range tree {
+		tree[i] = treeNode{value: int64(i), firstChild: -1, nextSibling: -1}
+	}
+	for i := 1; i < n; i++ {
+		parent := rng.Intn(i)
+		tree[i].nextSibling = tree[parent].firstChild
+		tree[parent].firstChild = int32(i)
+	}
+	return tree
+}
+
+func visitRecursive(tree []treeNode, i int32, visited *int) int64 {
+	*visited++
+	sum := tree[i].value
+	for c := tree[i].firstChild; c != -1; c = tree[c].nextSibling {
+		sum += visitRecursive(tree, c, visited)
+	}
+	return sum
+}
+
+func visitIterative(tree []treeNode, visited *int) int64 {
+	var sum int64
+	stack := []int32{0}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		*visited++
+		sum += tree[i].value
+		for c := tree[i].firstChild; c != -1; c = tree[c].nextSibling {
+			stack = append(stack, c)
+		}
+	}
+	return sum
+}
+
+func handleTreeTraversal(ctx context.Context, req *mcp.CallToolRequest, args TreeTraversalArgs) (*mcp.CallToolResult, TreeTraversalOutput, error) {
+	if args.Nodes < 1 || args.Nodes > 10000000 {
+		return nil, TreeTraversalOutput{}, fmt.Errorf("nodes deve estar entre 1 e 10000000")
+	}
+	if args.Method != "recursive" && args.Method != "iterative" {
+		return nil, TreeTraversalOutput{}, fmt.Errorf("method deve ser recursive ou iterative")
+	}
+
+	buildStart := time.Now()
+	tree := buildRandomTree(args.Nodes)
+	buildElapsed := time.Since(buildStart)
+
+	visited := 0
+	var checksum int64
+	startTime := time.Now()
+	if args.Method == "recursive" {
+		checksum = visitRecursive(tree, 0, &visited)
+	} else {
+		checksum = visitIterative(tree, &visited)
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, TreeTraversalOutput{
+		Nodes:         args.Nodes,
+		Method:        args.Method,
+		Visited:       visited,
+		Checksum:      checksum,
+		BuildMs:       float64(buildElapsed.Nanoseconds()) / 1e6,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// internPrefix pads every generated string past the small sizes Go can keep
+// inline, so each copy is a real heap allocation
+const internPrefix = "benchmark-mcp-interned-value-"
+
+func handleInternBench(ctx context.Context, req *mcp.CallToolRequest, args InternBenchArgs) (*mcp.CallToolResult, InternBenchOutput, error) {
+	if args.Total < 1 || args.Total > 5000000 {
+		return nil, InternBenchOutput{}, fmt.Errorf("total deve estar entre 1 e 5000000")
+	}
+	if args.Unique < 1 || args.Unique > args.Total {
+		return nil, InternBenchOutput{}, fmt.Errorf("unique deve estar entre 1 e total")
+	}
+
+	// Every string is built from bytes, as if just decoded off the wire;
+	// interning reuses the first copy instead of allocating another
+	values := make([]string, args.Total)
+	var interned map[string]string
+	if args.Intern {
+		interned = make(map[string]string, args.Unique)
+	}
+	buf := []byte(internPrefix)
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	elapsed, allocs := measureAllocs(func() {
+		for i := range values {
+			buf = strconv.AppendInt(buf[:len(internPrefix)], int64(i%args.Unique), 10)
+			if !args.Intern {
+				values[i] = string(buf)
+				continue
+			}
+			// Indexing with string(buf) doesn't allocate, so a hit costs nothing
+			s, ok := interned[string(buf)]
+			if !ok {
+				s = string(buf)
+				interned[s] = s
+			}
+			values[i] = s
+		}
+	})
+	// Retained bytes are the live heap growth once garbage is collected, and
+	// include the intern map. Concurrent requests make this approximate.
+	runtime.GC()
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(values)
+	runtime.KeepAlive(interned)
+
+	return nil, InternBenchOutput{
+		Unique:        args.Unique,
+		Total:         args.Total,
+		Intern:        args.Intern,
+		Allocations:   allocs,
+		RetainedBytes: max(int64(after.HeapAlloc)-int64(before.HeapAlloc), 0),
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// lockedMap is one mutex-guarded map; the single-lock baseline is just a
+// shardedMap with one shard
+type lockedMap struct {
+	mu sync.Mutex
+	m  map[int64]int64
+}
+
+type shardedMap []lockedMap
+
+func newShardedMap(shards, capacity int) shardedMap {
+	sm := make(shardedMap, shards)
+	for i := range sm {
+		sm[i].m = make(map[int64]int64, capacity/shards)
+	}
+	return sm
+}
+
+// shard spreads sequential keys with a Fibonacci hash so each goroutine's
+// key range doesn't land on one shard
+func (sm shardedMap) shard(key int64) *lockedMap {
+	return &sm[(uint64(key)*0x9E3779B97F4A7C15>>32)%uint64(len(sm))]
+}
+
+func (sm shardedMap) store(key, value int64) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	s.m[key] = value
+	s.mu.Unlock()
+}
+
+func (sm shardedMap) load(key int64) int64 {
+	s := sm.shard(key)
+	s.mu.Lock()
+	v := s.m[key]
+	s.mu.Unlock()
+	return v
+}
+
+func (sm shardedMap) len() int {
+	n := 0
+	for i := range sm {
+		n += len(sm[i].m)
+	}
+	return n
+}
+
+// runMapWorkload has every goroutine store and read back operations keys of
+// its own, so the map must end with exactly goroutines*operations entries
+func runMapWorkload(sm shardedMap, goroutines, operations int) time.Duration {
+	var wg sync.WaitGroup
+	var sink atomic.Int64
+	startTime := time.Now()
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(base int64) {
+			defer wg.Done()
+			var sum int64
+			for i := int64(0); i < int64(operations); i++ {
+				sm.store(base+i, i)
+				sum += sm.load(base + i)
+			}
+			sink.Add(sum)
+		}(int64(g) * int64(operations))
+	}
+	wg.Wait()
+	return time.Since(startTime)
+}
+
+func handleShardedMapBench(ctx context.Context, req *mcp.CallToolRequest, args ShardedMapBenchArgs) (*mcp.CallToolResult, ShardedMapBenchOutput, error) {
+	if args.Shards < 1 || args.Shards > 1024 {
+		return nil, ShardedMapBenchOutput{}, fmt.Errorf("shards deve estar entre 1 e 1024")
+	}
+	if args.Goroutines < 1 || args.Goroutines > 1000 {
+		return nil, ShardedMapBenchOutput{}, fmt.Errorf("goroutines deve estar entre 1 e 1000")
+	}
+	if args.Operations < 1 || args.Operations > 1000000 {
+		return nil, ShardedMapBenchOutput{}, fmt.Errorf("operations deve estar entre 1 e 1000000")
+	}
+	total := args.Goroutines * args.Operations
+	if total > 5000000 {
+		return nil, ShardedMapBenchOutput{}, fmt.Errorf("goroutines * operations deve ser no máximo 5000000")
+	}
+
+	single := newShardedMap(1, total)
+	singleElapsed := runMapWorkload(single, args.Goroutines, args.Operations)
+	sharded := newShardedMap(args.Shards, total)
+	shardedElapsed := runMapWorkload(sharded, args.Goroutines, args.Operations)
+
+	entries := sharded.len()
+	return nil, ShardedMapBenchOutput{
+		Shards:        args.Shards,
+		Goroutines:    args.Goroutines,
+		Operations:    args.Operations,
+		SingleMs:      float64(singleElapsed.Nanoseconds()) / 1e6,
+		ShardedMs:     float64(shardedElapsed.Nanoseconds()) / 1e6,
+		Speedup:       float64(singleElapsed) / float64(shardedElapsed),
+		Entries:       entries,
+		Correct:       entries == total && single.len() == total,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// profileMaxBytes bounds the gzipped pprof profile profile_tool will return
+const profileMaxBytes = 8 << 20
+
+// handleProfileTool runs one tool call under a CPU profile and returns the
+// profile as base64 pprof data. The profiler is process-wide, so samples
+// from concurrent requests land in it too, and only one profile can run at a
+// time, including /debug/pprof/profile.
+func handleProfileTool(ctx context.Context, req *mcp.CallToolRequest, args ProfileToolArgs) (*mcp.CallToolResult, ProfileToolOutput, error) {
+	if args.Tool == "profile_tool" {
+		return nil, ProfileToolOutput{}, fmt.Errorf("profile_tool não pode perfilar a si mesmo")
+	}
+	invoke, ok := toolInvokers[args.Tool]
+	if !ok {
+		return nil, ProfileToolOutput{}, fmt.Errorf("ferramenta desconhecida: %s", args.Tool)
+	}
+	// The target is called in-process, so the guards it would face as a
+	// direct call are applied here
+	if err := toolGuardError(args.Tool); err != nil {
+		return nil, ProfileToolOutput{}, err
+	}
+	raw, err := json.Marshal(args.Args)
+	if err != nil {
+		return nil, ProfileToolOutput{}, fmt.Errorf("argumentos inválidos: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := runtimepprof.StartCPUProfile(&buf); err != nil {
+		return nil, ProfileToolOutput{}, fmt.Errorf("não foi possível iniciar o perfil de CPU: %v", err)
+	}
+	out := ProfileToolOutput{Tool: args.Tool, ServerType: "go", SchemaVersion: outputSchemaVersion}
+	startTime := time.Now()
+	result, callErr := invoke(ctx, raw)
+	out.ElapsedMs = elapsedMs(startTime)
+	runtimepprof.StopCPUProfile()
+
+	if buf.Len() > profileMaxBytes {
+		return nil, ProfileToolOutput{}, fmt.Errorf("perfil de %d bytes excede o limite de %d", buf.Len(), profileMaxBytes)
+	}
+	if callErr != nil {
+		out.Error = callErr.Error()
+	} else {
+		out.Ok = true
+		out.Result = result
+	}
+	out.ProfileBytes = buf.Len()
+	out.Profile = base64.StdEncoding.EncodeToString(buf.Bytes())
+	return nil, out, nil
+}
+
+// countJSONValues counts every value, containers included, and every object
+// key in a decoded document, matching what streamJSONCount sees as tokens
+func countJSONValues(v interface{}) (values, fields int) {
+	values = 1
+	switch t := v.(type) {
+	case map[string]interface{}:
+		fields = len(t)
+		for _, child := range t {
+			cv, cf := countJSONValues(child)
+			values, fields = values+cv, fields+cf
+		}
+	case []interface{}:
+		for _, child := range t {
+			cv, cf := countJSONValues(child)
+			values, fields = values+cv, fields+cf
+		}
+	}
+	return values, fields
+}
+
+// streamJSONCount walks data token by token without building the document.
+// Each open container is on the stack; objects alternate key and value.
+func streamJSONCount(data string) (values, fields int, err error) {
+	type frame struct{ object, expectKey bool }
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var stack []frame
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF && len(stack) > 0 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		if err == io.EOF {
+			return values, fields, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		var top *frame
+		if len(stack) > 0 {
+			top = &stack[len(stack)-1]
+		}
+		switch {
+		case tok == json.Delim('}') || tok == json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 && stack[len(stack)-1].object {
+				stack[len(stack)-1].expectKey = true
+			}
+		case top != nil && top.object && top.expectKey:
+			fields++
+			top.expectKey = false
+		case tok == json.Delim('{'):
+			values++
+			stack = append(stack, frame{object: true, expectKey: true})
+		case tok == json.Delim('['):
+			values++
+			stack = append(stack, frame{})
+		default:
+			values++
+			if top != nil && top.object {
+				top.expectKey = true
+			}
+		}
+	}
+}
+
+func handleJSONDecodeBench(ctx context.Context, req *mcp.CallToolRequest, args JSONDecodeBenchArgs) (*mcp.CallToolResult, JSONDecodeBenchOutput, error) {
+	if len(args.Data) > 16<<20 {
+		return nil, JSONDecodeBenchOutput{}, fmt.Errorf("data deve ter no máximo 16 MiB")
+	}
+	if args.Mode != "unmarshal" && args.Mode != "stream" {
+		return nil, JSONDecodeBenchOutput{}, fmt.Errorf("mode deve ser unmarshal ou stream")
+	}
+
+	var values, fields int
+	var err error
+	elapsed, allocs := measureAllocs(func() {
+		if args.Mode == "stream" {
+			values, fields, err = streamJSONCount(args.Data)
+			return
+		}
+		var doc interface{}
+		if err = json.Unmarshal([]byte(args.Data), &doc); err == nil {
+			values, fields = countJSONValues(doc)
+		}
+	})
+	if err != nil {
+		return nil, JSONDecodeBenchOutput{}, fmt.Errorf("JSON inválido: %v", err)
+	}
+
+	return nil, JSONDecodeBenchOutput{
+		Mode:          args.Mode,
+		Bytes:         len(args.Data),
+		Values:        values,
+		Fields:        fields,
+		Allocations:   allocs,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// finalizerObject is larger than the 16-byte tiny allocator limit, since
+// finalizers on objects packed into a shared tiny block may never run
+type finalizerObject struct {
+	id      int64
+	payload [24]byte
+}
+
+// finalizerWait caps how long finalizer_bench waits for the finalizer
+// goroutine to drain after forcing collection
+const finalizerWait = 5 * time.Second
+
+func handleFinalizerBench(ctx context.Context, req *mcp.CallToolRequest, args FinalizerBenchArgs) (*mcp.CallToolResult, FinalizerBenchOutput, error) {
+	if args.Objects < 1 || args.Objects > 1000000 {
+		return nil, FinalizerBenchOutput{}, fmt.Errorf("objects deve estar entre 1 e 1000000")
+	}
+
+	var ran atomic.Int64
+	finalize := func(*finalizerObject) { ran.Add(1) }
+	startTime := time.Now()
+	for i := 0; i < args.Objects; i++ {
+		obj := &finalizerObject{id: int64(i)}
+		runtime.SetFinalizer(obj, finalize)
+	}
+	allocateElapsed := time.Since(startTime)
+
+	// Finalizers run on a single runtime goroutine after the GC that finds
+	// the objects unreachable, so keep collecting until they have all run
+	collectStart := time.Now()
+	deadline := collectStart.Add(finalizerWait)
+	for ran.Load() < int64(args.Objects) && time.Now().Before(deadline) && ctx.Err() == nil {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	collectElapsed := time.Since(collectStart)
+
+	return nil, FinalizerBenchOutput{
+		Objects:       args.Objects,
+		FinalizersRan: ran.Load(),
+		AllocateMs:    float64(allocateElapsed.Nanoseconds()) / 1e6,
+		CollectMs:     float64(collectElapsed.Nanoseconds()) / 1e6,
+		ElapsedMs:     elapsedMs(startTime),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// randomBigInt returns a digits-long decimal integer with a nonzero leading
+// digit, from a fixed seed so every run multiplies the same operands
+func randomBigInt(rng *rand.Rand, digits int) *big.Int {
+	b := make([]byte, digits)
+	b[0] = byte('1' + rng.Intn(9))
+	for i := 1; i < digits; i++ {
+		b[i] = byte('0' + rng.Intn(10))
+	}
+	n, _ := new(big.Int).SetString(string(b), 10)
+	return n
+}
+
+func handleBigMulBench(ctx context.Context, req *mcp.CallToolRequest, args BigMulBenchArgs) (*mcp.CallToolResult, BigMulBenchOutput, error) {
+	if args.Digits < 1 || args.Digits > 1000000 {
+		return nil, BigMulBenchOutput{}, fmt.Errorf("digits deve estar entre 1 e 1000000")
+	}
+
+	if args.Squarings < 0 || args.Squarings > 20 {
+		return nil, BigMulBenchOutput{}, fmt.Errorf("squarings deve estar entre 0 e 20")
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	x, y := randomBigInt(rng, args.Digits), randomBigInt(rng, args.Digits)
+	z := new(big.Int)
+	// math/big switches from schoolbook to Karatsuba above an internal size
+	// threshold; squaring has its own, cheaper path. exp raises x to
+	// 2^squarings, which Exp computes as that many successive squarings of a
+	// doubling operand.
+	var op func()
+	squarings := 0
+	switch args.Algorithm {
+	case "mul":
+		op = func() { z.Mul(x, y) }
+	case "square":
+		op = func() { z.Mul(x, x) }
+	case "exp":
+		squarings = args.Squarings
+		if squarings == 0 {
+			squarings = 4
+		}
+		// The result has digits*2^squarings digits; keep it within the
+		// largest product mul can produce
+		if args.Digits<<squarings > 2000000 {
+			return nil, BigMulBenchOutput{}, fmt.Errorf("digits*2^squarings deve ser no máximo 2000000")
+		}
+		exponent := new(big.Int).Lsh(big.NewInt(1), uint(squarings))
+		op = func() { z.Exp(x, exponent, nil) }
+	default:
+		return nil, BigMulBenchOutput{}, fmt.Errorf("algorithm deve ser mul, square ou exp")
+	}
+	startTime := time.Now()
+	op()
+	elapsed := time.Since(startTime)
+
+	return nil, BigMulBenchOutput{
+		Digits:        args.Digits,
+		Algorithm:     args.Algorithm,
+		Squarings:     squarings,
+		ResultBits:    z.BitLen(),
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handlePingPong(ctx context.Context, req *mcp.CallToolRequest, args PingPongArgs) (*mcp.CallToolResult, PingPongOutput, error) {
+	if args.Rounds < 1 || args.Rounds > 10000000 {
+		return nil, PingPongOutput{}, fmt.Errorf("rounds deve estar entre 1 e 10000000")
+	}
+
+	// Unbuffered channels force a handoff, and a goroutine switch, on every send
+	ping, pong := make(chan int), make(chan int)
+	go func() {
+		for v := range ping {
+			pong <- v + 1
+		}
+		close(pong)
+	}()
+
+	token := 0
+	startTime := time.Now()
+	for i := 0; i < args.Rounds; i++ {
+		ping <- token
+		token = <-pong
+	}
+	elapsed := time.Since(startTime)
+	close(ping)
+	<-pong
+	if token != args.Rounds {
+		return nil, PingPongOutput{}, fmt.Errorf("token perdido: esperado %d, recebido %d", args.Rounds, token)
+	}
+
+	return nil, PingPongOutput{
+		Rounds:        args.Rounds,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerRound:    float64(elapsed.Nanoseconds()) / float64(args.Rounds),
+		RoundsPerSec:  float64(args.Rounds) / elapsed.Seconds(),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// parseFieldsString converts data to a string, splits it on commas and parses
+// each trimmed field as an integer, allocating along the way
+func parseFieldsString(data []byte) (fields, numeric int, sum int64) {
+	for _, f := range strings.Split(string(data), ",") {
+		fields++
+		if v, err := strconv.ParseInt(strings.TrimSpace(f), 10, 64); err == nil {
+			numeric++
+			sum += v
+		}
+	}
+	return fields, numeric, sum
+}
+
+// parseFieldsBytes does the same walk over data in place: one pass, no
+// conversions, accepting the same optionally signed decimal fields
+func parseFieldsBytes(data []byte) (fields, numeric int, sum int64) {
+	for start := 0; start <= len(data); {
+		end := bytes.IndexByte(data[start:], ',')
+		if end == -1 {
+			end = len(data)
+		} else {
+			end += start
+		}
+		fields++
+		f := bytes.TrimSpace(data[start:end])
+		neg := false
+		if len(f) > 0 && (f[0] == '-' || f[0] == '+') {
+			neg = f[0] == '-'
+			f = f[1:]
+		}
+		// The magnitude may reach 2^63 only when negative; past that, like
+		// ParseInt, the field doesn't count as numeric
+		limit := uint64(math.MaxInt64)
+		if neg {
+			limit++
+		}
+		var u uint64
+		ok := len(f) > 0
+		for _, c := range f {
+			d := uint64(c - '0')
+			if c < '0' || c > '9' || u > (limit-d)/10 {
+				ok = false
+				break
+			}
+			u = u*10 + d
+		}
+		if ok {
+			v := int64(u)
+			if neg {
+				v = -v
+			}
+			numeric++
+			sum += v
+		}
+		start = end + 1
+	}
+	return fields, numeric, sum
+}
+
+func handleByteParseBench(ctx context.Context, req *mcp.CallToolRequest, args ByteParseBenchArgs) (*mcp.CallToolResult, ByteParseBenchOutput, error) {
+	if len(args.Data) > 1<<20 {
+		return nil, ByteParseBenchOutput{}, fmt.Errorf("data deve ter no máximo 1 MiB")
+	}
+	if args.Iterations < 1 || args.Iterations > 100000 {
+		return nil, ByteParseBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 100000")
+	}
+	if int64(len(args.Data))*int64(args.Iterations) > 1<<30 {
+		return nil, ByteParseBenchOutput{}, fmt.Errorf("len(data) * iterations deve ser no máximo %d", 1<<30)
+	}
+	var parse func([]byte) (int, int, int64)
+	switch args.Mode {
+	case "string":
+		parse = parseFieldsString
+	case "bytes":
+		parse = parseFieldsBytes
+	default:
+		return nil, ByteParseBenchOutput{}, fmt.Errorf("mode deve ser string ou bytes")
+	}
+
+	// Input arrives as bytes in both modes, the way a request body would
+	data := []byte(args.Data)
+	out := ByteParseBenchOutput{Mode: args.Mode, Iterations: args.Iterations, ServerType: "go", SchemaVersion: outputSchemaVersion}
+	elapsed, allocs := measureAllocs(func() {
+		for i := 0; i < args.Iterations; i++ {
+			out.Fields, out.Numeric, out.Sum = parse(data)
+		}
+	})
+	out.Allocations = allocs
+	out.ElapsedMs = float64(elapsed.Nanoseconds()) / 1e6
+	return nil, out, nil
+}
+
+func handleCacheSim(ctx context.Context, req *mcp.CallToolRequest, args CacheSimArgs) (*mcp.CallToolResult, CacheSimOutput, error) {
+	if args.Capacity < 1 || args.Capacity > 1000000 {
+		return nil, CacheSimOutput{}, fmt.Errorf("capacity deve estar entre 1 e 1000000")
+	}
+	if len(args.Accesses) < 1 || len(args.Accesses) > 1000000 {
+		return nil, CacheSimOutput{}, fmt.Errorf("accesses deve conter entre 1 e 1000000 chaves")
+	}
+	if args.Policy != "lru" && args.Policy != "fifo" {
+		return nil, CacheSimOutput{}, fmt.Errorf("policy deve ser lru ou fifo")
+	}
+
+	// The list runs from most to least recently inserted (fifo) or used (lru);
+	// the map finds a key's element so a hit is O(1) under both policies
+	order := list.New()
+	entries := make(map[int]*list.Element, args.Capacity)
+	out := CacheSimOutput{Capacity: args.Capacity, Policy: args.Policy, Accesses: len(args.Accesses), ServerType: "go", SchemaVersion: outputSchemaVersion}
+	startTime := time.Now()
+	for _, key := range args.Accesses {
+		if e, ok := entries[key]; ok {
+			out.Hits++
+			if args.Policy == "lru" {
+				order.MoveToFront(e)
+			}
+			continue
+		}
+		out.Misses++
+		if order.Len() == args.Capacity {
+			oldest := order.Back()
+			delete(entries, oldest.Value.(int))
+			order.Remove(oldest)
+			out.Evictions++
+		}
+		entries[key] = order.PushFront(key)
+	}
+	out.ElapsedMs = elapsedMs(startTime)
+	out.HitRate = float64(out.Hits) / float64(len(args.Accesses))
+	return nil, out, nil
+}
+
+func handleStrconvBench(ctx context.Context, req *mcp.CallToolRequest, args StrconvBenchArgs) (*mcp.CallToolResult, StrconvBenchOutput, error) {
+	if args.Count < 1 || args.Count > 1000000 {
+		return nil, StrconvBenchOutput{}, fmt.Errorf("count deve estar entre 1 e 1000000")
+	}
+	if args.Iterations < 1 || args.Iterations > 10000 {
+		return nil, StrconvBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000")
+	}
+	if args.Count*args.Iterations > 100000000 {
+		return nil, StrconvBenchOutput{}, fmt.Errorf("count * iterations deve ser no máximo 100000000")
+	}
+
+	// Inputs span the full value range so parsing cost isn't flattered by
+	// short strings; bools use every spelling ParseBool accepts
+	rng := rand.New(rand.NewSource(42))
+	inputs := make([]string, args.Count)
+	var parse func(string) float64
+	switch args.Type {
+	case "int":
+		for i := range inputs {
+			inputs[i] = strconv.FormatInt(rng.Int63()-rng.Int63(), 10)
+		}
+		parse = func(s string) float64 {
+			v, _ := strconv.ParseInt(s, 10, 64)
+			return float64(v)
+		}
+	case "float":
+		for i := range inputs {
+			inputs[i] = strconv.FormatFloat(rng.NormFloat64()*math.Pow(10, float64(rng.Intn(20)-10)), 'g', -1, 64)
+		}
+		parse = func(s string) float64 {
+			v, _ := strconv.ParseFloat(s, 64)
+			return v
+		}
+	case "bool":
+		spellings := []string{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False"}
+		for i := range inputs {
+			inputs[i] = spellings[rng.Intn(len(spellings))]
+		}
+		parse = func(s string) float64 {
+			if v, _ := strconv.ParseBool(s); v {
+				return 1
+			}
+			return 0
+		}
+	default:
+		return nil, StrconvBenchOutput{}, fmt.Errorf("type deve ser int, float ou bool")
+	}
+
+	// The sum keeps every parsed value live
+	var sum float64
+	startTime := time.Now()
+	for it := 0; it < args.Iterations; it++ {
+		for _, s := range inputs {
+			sum += parse(s)
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, StrconvBenchOutput{
+		Count:         args.Count,
+		Type:          args.Type,
+		Iterations:    args.Iterations,
+		Sum:           sum,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerParse:    float64(elapsed.Nanoseconds()) / float64(args.Count*args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+func handleSyscallBench(ctx context.Context, req *mcp.CallToolRequest, args SyscallBenchArgs) (*mcp.CallToolResult, SyscallBenchOutput, error) {
+	if args.Iterations < 1 || args.Iterations > 10000000 {
+		return nil, SyscallBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 10000000")
+	}
+
+	// syscall.Getpid enters the kernel on every call (neither Go nor libc
+	// caches it here) and has no side effects. The pid sum keeps calls live.
+	pid := syscall.Getpid()
+	var sum int64
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		sum += int64(syscall.Getpid())
+	}
+	elapsed := time.Since(startTime)
+	if sum != int64(pid)*int64(args.Iterations) {
+		return nil, SyscallBenchOutput{}, fmt.Errorf("getpid retornou valores inconsistentes")
+	}
+
+	return nil, SyscallBenchOutput{
+		Iterations:    args.Iterations,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		NsPerSyscall:  float64(elapsed.Nanoseconds()) / float64(args.Iterations),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// sortRecord is the struct sort_types orders by Score, then Name, so every
+// comparison may touch two fields
+type sortRecord struct {
+	ID    int
+	Name  string
+	Score float64
+}
+
+// randomSortString returns a 16-byte lowercase string; long enough that most
+// comparisons look past the first few bytes
+func randomSortString(rng *rand.Rand) string {
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = byte('a' + rng.Intn(26))
+	}
+	return string(b)
+}
+
+func handleSortTypes(ctx context.Context, req *mcp.CallToolRequest, args SortTypesArgs) (*mcp.CallToolResult, SortTypesOutput, error) {
+	if args.Count < 1 || args.Count > 2000000 {
+		return nil, SortTypesOutput{}, fmt.Errorf("count deve estar entre 1 e 2000000")
+	}
+
+	// Each case builds its slice plus the less function sort.Slice needs
+	rng := rand.New(rand.NewSource(args.Seed))
+	var data interface{}
+	var less func(i, j int) bool
+	generateStart := time.Now()
+	switch args.Type {
+	case "int":
+		v := make([]int, args.Count)
+		for i := range v {
+			v[i] = rng.Int()
+		}
+		data, less = v, func(i, j int) bool { return v[i] < v[j] }
+	case "float":
+		v := make([]float64, args.Count)
+		for i := range v {
+			v[i] = rng.Float64()
+		}
+		data, less = v, func(i, j int) bool { return v[i] < v[j] }
+	case "string":
+		v := make([]string, args.Count)
+		for i := range v {
+			v[i] = randomSortString(rng)
+		}
+		data, less = v, func(i, j int) bool { return v[i] < v[j] }
+	case "struct":
+		v := make([]sortRecord, args.Count)
+		for i := range v {
+			// Few distinct scores so the Name tie-break actually runs
+			v[i] = sortRecord{ID: i, Name: randomSortString(rng), Score: float64(rng.Intn(100))}
+		}
+		data, less = v, func(i, j int) bool {
+			if v[i].Score != v[j].Score {
+				return v[i].Score < v[j].Score
+			}
+			return v[i].Name < v[j].Name
+		}
+	default:
+		return nil, SortTypesOutput{}, fmt.Errorf("type deve ser int, float, string ou struct")
+	}
+	generateElapsed := time.Since(generateStart)
+
+	startTime := time.Now()
+	if args.Stable {
+		sort.SliceStable(data, less)
+	} else {
+		sort.Slice(data, less)
+	}
+	elapsed := time.Since(startTime)
+
+	return nil, SortTypesOutput{
+		Count:         args.Count,
+		Type:          args.Type,
+		Seed:          args.Seed,
+		Stable:        args.Stable,
+		Sorted:        sort.SliceIsSorted(data, less),
+		GenerateMs:    float64(generateElapsed.Nanoseconds()) / 1e6,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// rwGuarded is the shared value for rwmutex_bench. Reads sum every slot so
+// the read-side critical section is long enough for concurrent readers to
+// matter; writes bump every slot so a torn read would break the sum.
+type rwGuarded struct {
+	slots [64]int64
+}
+
+// runRWWorkload runs readers and writers doing operations each, with rlock
+// and lock as the read and write sides. It returns the duration, the
+// completed reads and writes, and whether every read saw a consistent value.
+func runRWWorkload(g *rwGuarded, readers, writers, operations int, rlock, runlock, lock, unlock func()) (time.Duration, int64, int64, bool) {
+	var wg sync.WaitGroup
+	var reads, writes atomic.Int64
+	var torn atomic.Bool
+	startTime := time.Now()
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				rlock()
+				var sum int64
+				for _, v := range g.slots {
+					sum += v
+				}
+				first := g.slots[0]
+				runlock()
+				if sum != first*int64(len(g.slots)) {
+					torn.Store(true)
+				}
+				reads.Add(1)
+			}
+		}()
+	}
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				lock()
+				for j := range g.slots {
+					g.slots[j]++
+				}
+				unlock()
+				writes.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Since(startTime), reads.Load(), writes.Load(), !torn.Load()
+}
+
+func handleRWMutexBench(ctx context.Context, req *mcp.CallToolRequest, args RWMutexBenchArgs) (*mcp.CallToolResult, RWMutexBenchOutput, error) {
+	if args.Readers < 0 || args.Readers > 1000 || args.Writers < 0 || args.Writers > 1000 {
+		return nil, RWMutexBenchOutput{}, fmt.Errorf("readers e writers devem estar entre 0 e 1000")
+	}
+	if args.Readers+args.Writers == 0 {
+		return nil, RWMutexBenchOutput{}, fmt.Errorf("readers + writers deve ser ao menos 1")
+	}
+	if args.Operations < 1 || args.Operations > 1000000 {
+		return nil, RWMutexBenchOutput{}, fmt.Errorf("operations deve estar entre 1 e 1000000")
+	}
+	if (args.Readers+args.Writers)*args.Operations > 20000000 {
+		return nil, RWMutexBenchOutput{}, fmt.Errorf("(readers + writers) * operations deve ser no máximo 20000000")
+	}
+
+	// The same workload runs under a RWMutex and under a plain Mutex, where
+	// readers exclude each other too
+	var rw sync.RWMutex
+	rwValue := &rwGuarded{}
+	rwElapsed, reads, writes, rwOK := runRWWorkload(rwValue, args.Readers, args.Writers, args.Operations, rw.RLock, rw.RUnlock, rw.Lock, rw.Unlock)
+	var mu sync.Mutex
+	muValue := &rwGuarded{}
+	muElapsed, muReads, muWrites, muOK := runRWWorkload(muValue, args.Readers, args.Writers, args.Operations, mu.Lock, mu.Unlock, mu.Lock, mu.Unlock)
+
+	expectedReads := int64(args.Readers) * int64(args.Operations)
+	expectedWrites := int64(args.Writers) * int64(args.Operations)
+	return nil, RWMutexBenchOutput{
+		Readers:    args.Readers,
+		Writers:    args.Writers,
+		Operations: args.Operations,
+		Reads:      reads,
+		Writes:     writes,
+		RWMutexMs:  float64(rwElapsed.Nanoseconds()) / 1e6,
+		MutexMs:    float64(muElapsed.Nanoseconds()) / 1e6,
+		Correct: rwOK && muOK &&
+			reads == expectedReads && writes == expectedWrites &&
+			muReads == expectedReads && muWrites == expectedWrites &&
+			rwValue.slots[0] == expectedWrites && muValue.slots[0] == expectedWrites,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+var (
+	fixtureCities = []string{"São Paulo", "Lisboa", "Porto Alegre", "Recife", "Curitiba", "Belo Horizonte", "Salvador", "Fortaleza"}
+	fixtureTags   = []string{"premium", "trial", "beta", "legacy", "partner", "internal", "churned", "vip"}
+)
+
+// fixtureRecord builds one customer-like record: strings, numbers, booleans,
+// a nested object and two arrays, the shapes the JSON tools walk
+func fixtureRecord(rng *rand.Rand, id int) map[string]interface{} {
+	tags := make([]interface{}, 1+rng.Intn(4))
+	for i := range tags {
+		tags[i] = fixtureTags[rng.Intn(len(fixtureTags))]
+	}
+	history := make([]interface{}, rng.Intn(5))
+	for i := range history {
+		history[i] = map[string]interface{}{
+			"day":    rng.Intn(365) + 1,
+			"amount": math.Round(rng.Float64()*100000) / 100,
+			"status": []string{"paid", "pending", "refunded"}[rng.Intn(3)],
+		}
+	}
+	name := randomSortString(rng)[:8]
+	return map[string]interface{}{
+		"id":     id,
+		"name":   name,
+		"email":  name + "@example.com",
+		"active": rng.Intn(4) != 0,
+		"score":  math.Round(rng.Float64()*10000) / 100,
+		"tags":   tags,
+		"address": map[string]interface{}{
+			"street": fmt.Sprintf("Rua %s, %d", randomSortString(rng)[:6], rng.Intn(2000)+1),
+			"city":   fixtureCities[rng.Intn(len(fixtureCities))],
+			"zip":    fmt.Sprintf("%05d-%03d", rng.Intn(100000), rng.Intn(1000)),
+		},
+		"history": history,
+	}
+}
+
+// fixtureHeadroomBytes leaves room under PROCESS_MAX_BYTES for the record
+// that crosses the target and for the arguments wrapped around the document
+const fixtureHeadroomBytes = 4096
+
+// fixtureMaxKB is the largest size_kb whose document still fits through
+// process_json_data's PROCESS_MAX_BYTES check
+func fixtureMaxKB() int {
+	return max(1, min(10240, (cfg.ProcessMaxBytes-fixtureHeadroomBytes)/1024))
+}
+
+func handleFixtureJSON(ctx context.Context, req *mcp.CallToolRequest, args FixtureJSONArgs) (*mcp.CallToolResult, FixtureJSONOutput, error) {
+	if maxKB := fixtureMaxKB(); args.SizeKB < 1 || args.SizeKB > maxKB {
+		return nil, FixtureJSONOutput{}, fmt.Errorf("size_kb deve estar entre 1 e %d", maxKB)
+	}
+
+	// Records are added until the encoded document reaches the target; each
+	// one is measured on its own, plus the comma that joins it
+	rng := rand.New(rand.NewSource(args.Seed))
+	records := []interface{}{}
+	data := map[string]interface{}{
+		"version":  1,
+		"seed":     args.Seed,
+		"metadata": map[string]interface{}{"generator": "fixture_json", "server_type": "go"},
+	}
+	meta, _ := json.Marshal(data)
+	size := len(meta) + len(`,"records":[]`)
+	target := args.SizeKB * 1024
+	for size < target {
+		rec := fixtureRecord(rng, len(records)+1)
+		encoded, err := json.Marshal(rec)
+		if err != nil {
+			return nil, FixtureJSONOutput{}, fmt.Errorf("falha ao gerar registro: %v", err)
+		}
+		if len(records) > 0 {
+			size++
+		}
+		size += len(encoded)
+		records = append(records, rec)
+	}
+	data["records"] = records
+	if n := countKeys(data); n > cfg.ProcessMaxKeys {
+		return nil, FixtureJSONOutput{}, inputLimitError("size_kb", "PROCESS_MAX_KEYS", cfg.ProcessMaxKeys, n)
+	}
+
+	return nil, FixtureJSONOutput{
+		SizeKB:        args.SizeKB,
+		Seed:          args.Seed,
+		Records:       len(records),
+		Bytes:         size,
+		Data:          data,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// runBenchTask is one unit of worker_pool_bench work: a short CPU mix, then
+// work of waiting, as a handler blocked on I/O would. It returns a value
+// derived from the mix so the CPU part stays live. The wait ends early once
+// ctx is done.
+func runBenchTask(ctx context.Context, id int, work time.Duration) uint64 {
+	x := uint64(id) + 1
+	for i := 0; i < 256; i++ {
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+	}
+	if work > 0 {
+		select {
+		case <-time.After(work):
+		case <-ctx.Done():
+		}
+	}
+	return x
+}
+
+func handleWorkerPoolBench(ctx context.Context, req *mcp.CallToolRequest, args WorkerPoolBenchArgs) (*mcp.CallToolResult, WorkerPoolBenchOutput, error) {
+	if args.Tasks < 1 || args.Tasks > 20000 {
+		return nil, WorkerPoolBenchOutput{}, fmt.Errorf("tasks deve estar entre 1 e 20000")
+	}
+	if args.Workers < 1 || args.Workers > 1000 {
+		return nil, WorkerPoolBenchOutput{}, fmt.Errorf("workers deve estar entre 1 e 1000")
+	}
+	if args.WorkMs < 0 || args.WorkMs > 1000 {
+		return nil, WorkerPoolBenchOutput{}, fmt.Errorf("work_ms deve estar entre 0 e 1000")
+	}
+	// The pool needs about tasks/workers rounds of work_ms
+	if (args.Tasks+args.Workers-1)/args.Workers*args.WorkMs > 30000 {
+		return nil, WorkerPoolBenchOutput{}, fmt.Errorf("tasks / workers * work_ms deve ser no máximo 30000")
+	}
+
+	work := time.Duration(args.WorkMs) * time.Millisecond
+	var completed atomic.Int64
+	var sink atomic.Uint64
+
+	// Fixed pool: workers goroutines pull task ids from a channel
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+	startTime := time.Now()
+	for w := 0; w < args.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range tasks {
+				sink.Add(runBenchTask(ctx, id, work))
+				completed.Add(1)
+			}
+		}()
+	}
+	for id := 0; id < args.Tasks; id++ {
+		if ctx.Err() != nil {
+			break
+		}
+		tasks <- id
+	}
+	close(tasks)
+	wg.Wait()
+	poolElapsed := time.Since(startTime)
+	if err := ctx.Err(); err != nil {
+		return nil, WorkerPoolBenchOutput{}, fmt.Errorf("worker_pool_bench cancelado: %w", err)
+	}
+
+	// One goroutine per task, all started at once
+	startTime = time.Now()
+	for id := 0; id < args.Tasks; id++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sink.Add(runBenchTask(ctx, id, work))
+			completed.Add(1)
+		}()
+	}
+	wg.Wait()
+	perTaskElapsed := time.Since(startTime)
+	if err := ctx.Err(); err != nil {
+		return nil, WorkerPoolBenchOutput{}, fmt.Errorf("worker_pool_bench cancelado: %w", err)
+	}
+
+	return nil, WorkerPoolBenchOutput{
+		Tasks:         args.Tasks,
+		Workers:       args.Workers,
+		WorkMs:        args.WorkMs,
+		PoolMs:        float64(poolElapsed.Nanoseconds()) / 1e6,
+		PerTaskMs:     float64(perTaskElapsed.Nanoseconds()) / 1e6,
+		Completed:     completed.Load(),
+		Correct:       completed.Load() == 2*int64(args.Tasks),
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
+}
+
+// simulatedErrors maps each simulate_error code to the JSON-RPC error it
+// returns as a protocol error
+var simulatedErrors = map[string]int64{
+	"parse_error":       jsonrpc.CodeParseError,
+	"invalid_request":   jsonrpc.CodeInvalidRequest,
+	"method_not_found":  jsonrpc.CodeMethodNotFound,
+	"invalid_params":    jsonrpc.CodeInvalidParams,
+	"internal_error":    jsonrpc.CodeInternalError,
+	"server_overloaded": codeServerOverloaded,
+}
+
+// handleSimulateError fails on demand. "tool_error" is a failed tool result
+// (isError), every simulatedErrors code is a JSON-RPC error response, and
+// "ok" succeeds, as a baseline for the error-path latency.
+func handleSimulateError(ctx context.Context, req *mcp.CallToolRequest, args SimulateErrorArgs) (*mcp.CallToolResult, SimulateErrorOutput, error) {
+	switch args.Code {
+	case "ok":
+		return nil, SimulateErrorOutput{Code: args.Code, ServerType: "go", SchemaVersion: outputSchemaVersion}, nil
+	case "tool_error":
+		return nil, SimulateErrorOutput{}, fmt.Errorf("erro simulado da ferramenta")
+	}
+	code, ok := simulatedErrors[args.Code]
+	if !ok {
+		codes := []string{"ok", "tool_error"}
+		for c := range simulatedErrors {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+		return nil, SimulateErrorOutput{}, &jsonrpc.Error{
+			Code:    jsonrpc.CodeInvalidParams,
+			Message: fmt.Sprintf("code desconhecido %q: deve ser um de %s", args.Code, strings.Join(codes, ", ")),
+		}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"simulated": true, "code": args.Code, "server_type": "go"})
+	return nil, SimulateErrorOutput{}, &jsonrpc.Error{
+		Code:    code,
+		Message: fmt.Sprintf("erro simulado: %s", args.Code),
+		Data:    data,
+	}
+}
+
+func handleMemcopyBench(ctx context.Context, req *mcp.CallToolRequest, args MemcopyBenchArgs) (*mcp.CallToolResult, MemcopyBenchOutput, error) {
+	if args.SizeMB < 1 || args.SizeMB > 256 {
+		return nil, MemcopyBenchOutput{}, fmt.Errorf("size_mb deve estar entre 1 e 256")
+	}
+	if args.Iterations < 1 || args.Iterations > 1000 {
+		return nil, MemcopyBenchOutput{}, fmt.Errorf("iterations deve estar entre 1 e 1000")
+	}
+	if args.SizeMB*args.Iterations > 32768 {
+		return nil, MemcopyBenchOutput{}, fmt.Errorf("size_mb * iterations deve ser no máximo 32768")
+	}
+
+	// Both buffers are written once up front so page faults from first touch
+	// stay out of the timed loop
+	size := args.SizeMB << 20
+	src, dst := make([]byte, size), make([]byte, size)
+	for i := range src {
+		src[i] = byte(i)
+	}
+	copy(dst, src)
+
+	// Changing one source byte per pass and checking it arrived proves every
+	// copy really ran
+	startTime := time.Now()
+	for i := 0; i < args.Iterations; i++ {
+		src[size-1] = byte(i)
+		copy(dst, src)
+		if dst[size-1] != byte(i) {
+			return nil, MemcopyBenchOutput{}, fmt.Errorf("cópia incompleta na iteração %d", i)
+		}
+	}
+	elapsed := time.Since(startTime)
+
+	copied := int64(size) * int64(args.Iterations)
+	return nil, MemcopyBenchOutput{
+		SizeMB:        args.SizeMB,
+		Iterations:    args.Iterations,
+		BytesCopied:   copied,
+		ElapsedMs:     float64(elapsed.Nanoseconds()) / 1e6,
+		BytesPerSec:   float64(copied) / elapsed.Seconds(),
+		GBPerSec:      float64(copied) / elapsed.Seconds() / 1e9,
+		ServerType:    "go",
+		SchemaVersion: outputSchemaVersion,
+	}, nil
 }
 
-type FetchDataOutput struct {
-	URL            string `json:"url"`
-	StatusCode     int    `json:"status_code"`
-	ResponseTimeMs int64  `json:"response_time_ms"`
-	Error          string `json:"error,omitempty"`
-	ServerType     string `json:"server_type"`
+// idempotencyEntry is a recorded /mcp response. done is closed once the
+// original request finishes, so concurrent duplicates wait and replay it.
+type idempotencyEntry struct {
+	done     chan struct{}
+	bodyHash [sha256.Size]byte // request body the key was first used with
+	status   int
+	header   http.Header
+	body     []byte
+	expires  time.Time
+	ok       bool // false when the original failed and must not be replayed
 }
 
-type ProcessDataOutput struct {
-	OriginalKeys    []string               `json:"original_keys"`
-	TransformedData map[string]interface{} `json:"transformed_data"`
-	ServerType      string                 `json:"server_type"`
+// idempotencyCache replays responses for repeated Idempotency-Key headers
+// within the TTL, so harness retries don't re-execute side-effecting tools.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+	ttl     time.Duration
+	stop    chan struct{}
 }
 
-type DatabaseOutput struct {
-	Query      string `json:"query"`
-	DelayMs    int    `json:"delay_ms"`
-	Timestamp  string `json:"timestamp"`
-	ServerType string `json:"server_type"`
+const idempotencyMaxEntries = 10000
+
+// idempotencyMaxResponseBytes caps a single recorded response; larger ones are
+// still served but not replayed
+const idempotencyMaxResponseBytes = 1 << 20
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	c := &idempotencyCache{entries: make(map[string]*idempotencyEntry), ttl: ttl, stop: make(chan struct{})}
+	go c.evictLoop()
+	return c
 }
 
-// HTTP client with timeout for external requests
-var httpClient = &http.Client{Timeout: 10 * time.Second}
+// Shutdown stops the eviction loop and drops every recorded response
+func (c *idempotencyCache) Shutdown(ctx context.Context) error {
+	close(c.stop)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Printf("Idempotency cache: dropped %d entries\n", len(c.entries))
+	c.entries = make(map[string]*idempotencyEntry)
+	return nil
+}
 
-// Tool handlers
-func handleFibonacci(ctx context.Context, req *mcp.CallToolRequest, args FibonacciArgs) (*mcp.CallToolResult, FibonacciOutput, error) {
-	if args.N < 0 || args.N > 40 {
-		return nil, FibonacciOutput{}, fmt.Errorf("n deve estar entre 0 e 40")
+func (c *idempotencyCache) evictLoop() {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		var now time.Time
+		select {
+		case now = <-ticker.C:
+		case <-c.stop:
+			return
+		}
+		c.mu.Lock()
+		for key, e := range c.entries {
+			select {
+			case <-e.done:
+				if now.After(e.expires) {
+					delete(c.entries, key)
+				}
+			default: // still in flight
+			}
+		}
+		c.mu.Unlock()
 	}
+}
 
-	var fib func(int) int
-	fib = func(x int) int {
-		if x <= 1 {
-			return x
+// recordingWriter tees the response into a buffer while still streaming it,
+// giving up on the copy once it passes idempotencyMaxResponseBytes
+type recordingWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.overflow {
+		if w.body.Len()+len(p) > idempotencyMaxResponseBytes {
+			w.overflow = true
+			w.body = bytes.Buffer{}
+		} else {
+			w.body.Write(p)
 		}
-		return fib(x-1) + fib(x-2)
 	}
+	return w.ResponseWriter.Write(p)
+}
 
-	return nil, FibonacciOutput{
-		Input:      args.N,
-		Result:     fib(args.N),
-		ServerType: "go",
-	}, nil
+func (w *recordingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
 
-func handleFetchData(ctx context.Context, req *mcp.CallToolRequest, args FetchDataArgs) (*mcp.CallToolResult, FetchDataOutput, error) {
-	startTime := time.Now()
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
 
-	resp, err := httpClient.Get(args.Endpoint)
-	responseTimeMs := time.Since(startTime).Milliseconds()
+func idempotencyMiddleware(c *idempotencyCache, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Scope keys to the MCP session so clients can't see each other's responses
+		key = r.Header.Get("Mcp-Session-Id") + "|" + key
 
-	if err != nil {
-		return nil, FetchDataOutput{
-			URL:            args.Endpoint,
-			StatusCode:     0,
-			ResponseTimeMs: responseTimeMs,
-			Error:          err.Error(),
-			ServerType:     "go",
-		}, nil
+		// The body is hashed so a reused key with different params is
+		// rejected instead of replaying the wrong response
+		body, ok := readLimitedBody(w, r)
+		if !ok {
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		bodyHash := sha256.Sum256(body)
+
+		c.mu.Lock()
+		if e, ok := c.entries[key]; ok {
+			c.mu.Unlock()
+			if e.bodyHash != bodyHash {
+				http.Error(w, "Idempotency-Key já foi usada com um corpo diferente", http.StatusUnprocessableEntity)
+				return
+			}
+			select {
+			case <-e.done:
+			case <-r.Context().Done():
+				return
+			}
+			if e.ok && time.Now().Before(e.expires) {
+				for k, v := range e.header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(e.status)
+				w.Write(e.body)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(c.entries) >= idempotencyMaxEntries {
+			c.mu.Unlock()
+			next.ServeHTTP(w, r)
+			return
+		}
+		e := &idempotencyEntry{done: make(chan struct{}), bodyHash: bodyHash}
+		c.entries[key] = e
+		c.mu.Unlock()
+
+		rec := &recordingWriter{ResponseWriter: w}
+		defer func() {
+			e.status = rec.status
+			e.header = w.Header().Clone()
+			e.body = rec.body.Bytes()
+			e.expires = time.Now().Add(c.ttl)
+			// 5xx and oversized responses are not replayed, so a retry gets a
+			// fresh attempt
+			e.ok = rec.status != 0 && rec.status < 500 && !rec.overflow
+			if !e.ok {
+				c.mu.Lock()
+				delete(c.entries, key)
+				c.mu.Unlock()
+			}
+			close(e.done)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// knownTools records every tool name offered by the server, including disabled
+// ones, so DISABLED_TOOLS typos can be reported at startup.
+var knownTools = make(map[string]bool)
+
+// toolInvoker calls a registered tool in-process with raw JSON arguments,
+// returning its structured output. Used by batch to fan out internal calls.
+type toolInvoker func(ctx context.Context, args json.RawMessage) (interface{}, error)
+
+// toolInvokers holds every enabled tool; written only during startup registration
+var toolInvokers = make(map[string]toolInvoker)
+
+// toolSchemas maps every enabled tool to its input schema, as the SDK derives
+// it from the argument struct; logged at startup
+var toolSchemas = make(map[string]*jsonschema.Schema)
+
+// logToolSchemas prints every enabled tool with its compact input schema
+func logToolSchemas() {
+	names := make([]string, 0, len(toolSchemas))
+	for name := range toolSchemas {
+		names = append(names, name)
 	}
-	defer resp.Body.Close()
+	sort.Strings(names)
+	fmt.Printf("Registered tools (%d):\n", len(names))
+	for _, name := range names {
+		schema, err := json.Marshal(toolSchemas[name])
+		if err != nil {
+			schema = []byte(err.Error())
+		}
+		fmt.Printf("  %s %s\n", name, schema)
+	}
+}
 
-	return nil, FetchDataOutput{
-		URL:            args.Endpoint,
-		StatusCode:     resp.StatusCode,
-		ResponseTimeMs: responseTimeMs,
-		ServerType:     "go",
-	}, nil
+// errToolPanic is reported to the client when a tool handler panics
+var errToolPanic = errors.New("erro interno do servidor")
+
+// logToolPanic counts and logs a panic recovered from a tool handler
+func logToolPanic(name string, v interface{}) {
+	total := recoveredPanics.Add(1)
+	fmt.Fprintf(os.Stderr, "Recovered panic in tool %s (%d so far): %v\n%s", name, total, v, debug.Stack())
 }
 
-func handleProcessData(ctx context.Context, req *mcp.CallToolRequest, args ProcessDataArgs) (*mcp.CallToolResult, ProcessDataOutput, error) {
-	var transformStrings func(interface{}) interface{}
-	transformStrings = func(obj interface{}) interface{} {
-		switch v := obj.(type) {
-		case map[string]interface{}:
-			result := make(map[string]interface{})
-			for key, val := range v {
-				result[key] = transformStrings(val)
+// runningTools counts tool handlers currently executing. The SDK runs them on
+// its own goroutines, which can outlive the HTTP request that started them.
+var runningTools atomic.Int64
+
+// toolContext is the context a tool handler runs with: the SDK's, also
+// cancelled once shutdown cuts off in-flight work
+func toolContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(handlersCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// recoverTool wraps a typed handler so a panic becomes an error tool result
+func recoverTool[In, Out any](name string, handler mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, in In) (res *mcp.CallToolResult, out Out, err error) {
+		runningTools.Add(1)
+		defer runningTools.Add(-1)
+		ctx, cancel := toolContext(ctx)
+		defer cancel()
+		defer func() {
+			if v := recover(); v != nil {
+				logToolPanic(name, v)
+				var zero Out
+				res, out, err = nil, zero, errToolPanic
 			}
-			return result
-		case []interface{}:
-			result := make([]interface{}, len(v))
-			for i, val := range v {
-				result[i] = transformStrings(val)
+		}()
+		return handler(ctx, req, in)
+	}
+}
+
+// recoverRawTool is recoverTool for handlers registered through addRawTool
+func recoverRawTool(name string, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (res *mcp.CallToolResult, err error) {
+		runningTools.Add(1)
+		defer runningTools.Add(-1)
+		ctx, cancel := toolContext(ctx)
+		defer cancel()
+		defer func() {
+			if v := recover(); v != nil {
+				logToolPanic(name, v)
+				res = &mcp.CallToolResult{
+					Content: []mcp.Content{&mcp.TextContent{Text: errToolPanic.Error()}},
+					IsError: true,
+				}
+				err = nil
 			}
-			return result
-		case string:
-			return strings.ToUpper(v)
-		default:
-			return v
-		}
+		}()
+		return handler(ctx, req)
 	}
+}
 
-	transformed := transformStrings(args.Data).(map[string]interface{})
-	originalKeys := make([]string, 0, len(args.Data))
-	for k := range args.Data {
-		originalKeys = append(originalKeys, k)
+// addTool registers a typed tool unless it is listed in DISABLED_TOOLS
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	knownTools[tool.Name] = true
+	if cfg.DisabledTools[tool.Name] {
+		return
+	}
+	handler = recoverTool(tool.Name, handler)
+	mcp.AddTool(server, tool, handler)
+	if tool.InputSchema != nil {
+		toolSchemas[tool.Name] = tool.InputSchema.(*jsonschema.Schema)
+	} else {
+		toolSchemas[tool.Name] = mustSchema[In]()
 	}
 
-	return nil, ProcessDataOutput{
-		OriginalKeys:    originalKeys,
-		TransformedData: transformed,
-		ServerType:      "go",
-	}, nil
+	name := tool.Name
+	toolInvokers[name] = func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		raw = withToolDefaults(name, raw)
+		var in In
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &in); err != nil {
+				return nil, fmt.Errorf("argumentos inválidos: %v", err)
+			}
+		}
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name, Arguments: raw}}
+		_, out, err := handler(ctx, req, in)
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
 }
 
-func handleDatabaseQuery(ctx context.Context, req *mcp.CallToolRequest, args DatabaseQueryArgs) (*mcp.CallToolResult, DatabaseOutput, error) {
-	if args.DelayMs < 0 || args.DelayMs > 5000 {
-		return nil, DatabaseOutput{}, fmt.Errorf("delay_ms deve estar entre 0 e 5000")
+// experimentalTools records the tools gated behind EXPERIMENTAL, for the
+// startup log
+var experimentalTools = make(map[string]bool)
+
+// addExperimentalTool is addTool for tools only offered with EXPERIMENTAL=1.
+// Gated tools still count as known, so DISABLED_TOOLS and TOOL_DEFAULTS
+// entries naming them aren't reported as typos.
+func addExperimentalTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	experimentalTools[tool.Name] = true
+	if !cfg.Experimental {
+		knownTools[tool.Name] = true
+		return
 	}
+	addTool(server, tool, handler)
+}
 
-	time.Sleep(time.Duration(args.DelayMs) * time.Millisecond)
+// addRawTool is addTool for tools that decode their own arguments
+func addRawTool(server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandler) {
+	knownTools[tool.Name] = true
+	if cfg.DisabledTools[tool.Name] {
+		return
+	}
+	handler = recoverRawTool(tool.Name, handler)
+	server.AddTool(tool, handler)
+	toolSchemas[tool.Name] = tool.InputSchema.(*jsonschema.Schema)
 
-	return nil, DatabaseOutput{
-		Query:      args.Query,
-		DelayMs:    args.DelayMs,
-		Timestamp:  time.Now().UTC().Format(time.RFC3339),
-		ServerType: "go",
-	}, nil
+	name := tool.Name
+	toolInvokers[name] = func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name, Arguments: withToolDefaults(name, raw)}}
+		res, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if res.IsError {
+			if text, ok := res.Content[0].(*mcp.TextContent); ok {
+				return nil, fmt.Errorf("%s", text.Text)
+			}
+			return nil, fmt.Errorf("erro na ferramenta %s", name)
+		}
+		return res.StructuredContent, nil
+	}
 }
 
-func main() {
-	// Create server
+// newMCPServer creates an MCP server with every benchmark tool registered
+func newMCPServer() *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "BenchmarkGoServer",
 		Version: "1.0.0",
 	}, nil)
+	// Metrics wrap the latency floor so recorded latency matches what clients see
+	server.AddReceivingMiddleware(requestLogMiddleware, metricsMiddleware, memoryGuardMiddleware, goroutineGuardMiddleware, minResponseMiddleware, toolDefaultsMiddleware)
 
 	// Register tools
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "calculate_fibonacci",
 		Description: "Calcula o N-ésimo número de Fibonacci de forma recursiva",
 	}, handleFibonacci)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "fetch_external_data",
 		Description: "Faz uma requisição HTTP GET para uma API externa",
 	}, handleFetchData)
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "process_json_data",
-		Description: "Recebe um JSON, valida e transforma (uppercase em campos string)",
-	}, handleProcessData)
+	addRawTool(server, &mcp.Tool{
+		Name:         "process_json_data",
+		Description:  "Recebe um JSON, valida e transforma (uppercase em campos string)",
+		InputSchema:  mustSchema[ProcessDataArgs](),
+		OutputSchema: mustSchema[ProcessDataOutput](),
+	}, handleProcessDataRaw)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "simulate_database_query",
 		Description: "Simula uma query de banco de dados com delay configurável",
 	}, handleDatabaseQuery)
 
+	addTool(server, &mcp.Tool{
+		Name:        "regex_compile",
+		Description: "Compila uma expressão regular N vezes (sem executar match) para medir o custo de compilação",
+	}, handleRegexCompile)
+
+	addTool(server, &mcp.Tool{
+		Name:        "cache_op",
+		Description: "Executa get/set/delete em um cache compartilhado em memória (sync.Map)",
+	}, handleCacheOp)
+
+	addTool(server, &mcp.Tool{
+		Name:        "json_query",
+		Description: "Extrai um valor de um documento JSON usando um caminho estilo JSONPath (notação de ponto/colchetes)",
+	}, handleJSONQuery)
+
+	addTool(server, &mcp.Tool{
+		Name:        "collatz",
+		Description: "Calcula o número de passos da sequência de Collatz até 1 e o maior valor atingido",
+	}, handleCollatz)
+
+	addTool(server, &mcp.Tool{
+		Name:        "spawn_goroutines",
+		Description: "Cria N goroutines de vida curta sincronizadas por WaitGroup e mede o overhead do scheduler",
+	}, handleSpawnGoroutines)
+
+	addTool(server, &mcp.Tool{
+		Name:        "pi_digits",
+		Description: "Calcula pi com N casas decimais usando a fórmula de Machin com math/big",
+	}, handlePiDigits)
+
+	addTool(server, &mcp.Tool{
+		Name:        "lock_contention",
+		Description: "Mede a contenção de sync.Mutex, sync.RWMutex ou atomic em um contador compartilhado",
+	}, handleLockContention)
+
+	addTool(server, &mcp.Tool{
+		Name:        "enqueue",
+		Description: "Insere um item na fila em memória limitada (falha ou bloqueia quando cheia)",
+	}, handleEnqueue)
+
+	addTool(server, &mcp.Tool{
+		Name:        "dequeue",
+		Description: "Remove um item da fila em memória limitada (falha ou bloqueia quando vazia)",
+	}, handleDequeue)
+
+	addTool(server, &mcp.Tool{
+		Name:        "struct_map",
+		Description: "Converte o JSON em uma struct fixa e de volta N vezes (encoding/json via reflection)",
+	}, handleStructMap)
+
+	addTool(server, &mcp.Tool{
+		Name:        "time_format",
+		Description: "Interpreta uma data com time.Parse e a reformata N vezes",
+	}, handleTimeFormat)
+
+	addTool(server, &mcp.Tool{
+		Name:        "walk_filesystem",
+		Description: "Percorre uma árvore de diretórios (restrita a WALK_BASE_DIR) contando arquivos e bytes",
+	}, handleWalkFilesystem)
+
+	addTool(server, &mcp.Tool{
+		Name:        "batch",
+		Description: "Executa várias ferramentas registradas em uma única requisição MCP (sequencial ou em paralelo)",
+	}, handleBatch)
+
+	addTool(server, &mcp.Tool{
+		Name:        "bst_benchmark",
+		Description: "Executa inserts/lookups/deletes em uma árvore binária de busca (carga intensa em ponteiros e GC)",
+	}, handleBSTBenchmark)
+
+	addTool(server, &mcp.Tool{
+		Name:        "sort_numbers",
+		Description: "Ordena números aleatórios com quicksort ou mergesort implementados à mão, contando comparações e trocas opcionalmente",
+	}, handleSortNumbers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "edit_distance",
+		Description: "Calcula a distância de Levenshtein entre duas strings via programação dinâmica",
+	}, handleEditDistance)
+
+	addTool(server, &mcp.Tool{
+		Name:        "env_info",
+		Description: "Retorna o ambiente de execução: SO, arquitetura, versão do Go, CPUs, GOMAXPROCS, limites de cgroup e GOGC/GODEBUG",
+	}, handleEnvInfo)
+
+	addTool(server, &mcp.Tool{
+		Name:        "math_bench",
+		Description: "Executa sin/cos/exp/log/sqrt N vezes e retorna checksum e tempo (throughput de ponto flutuante)",
+	}, handleMathBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "factorize",
+		Description: "Fatora n em primos por divisão experimental até sqrt(n), opcionalmente dividindo o intervalo entre goroutines",
+	}, handleFactorize)
+
+	addTool(server, &mcp.Tool{
+		Name:        "boxing_bench",
+		Description: "Compara armazenar e ler ints via interface{} versus slots tipados, retornando tempos e alocações",
+	}, handleBoxingBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "copy_bench",
+		Description: "Passa size structs de 1 KiB por valor ou por ponteiro a uma função, iterations vezes, e mede o custo da cópia",
+	}, handleCopyBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "histogram",
+		Description: "Agrupa values em buckets de largura igual entre o mínimo e o máximo, retornando limites e contagens",
+	}, handleHistogram)
+
+	addTool(server, &mcp.Tool{
+		Name:        "append_bench",
+		Description: "Faz append de count elementos numa slice, com ou sem pré-alocação, e conta as realocações pelo crescimento da capacidade",
+	}, handleAppendBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "pipeline",
+		Description: "Passa dados por stages estágios sequenciais, cada um com work_ms de CPU e uma transformação, retornando o tempo por estágio",
+	}, handlePipeline)
+
+	addTool(server, &mcp.Tool{
+		Name:        "marshal_bench",
+		Description: "Monta um mapa aninhado com depth níveis e breadth filhos por nível e o serializa em JSON iterations vezes",
+	}, handleMarshalBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "context_bench",
+		Description: "Deriva contextos aninhados (WithValue/WithCancel) até depth níveis e lê valores de volta, iterations vezes",
+	}, handleContextBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "defer_bench",
+		Description: "Executa uma função iterations vezes liberando um lock com defer ou inline e retorna o tempo por chamada",
+	}, handleDeferBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "memory_access_bench",
+		Description: "Aloca size_mb de memória e soma os elementos em ordem sequencial ou aleatória para expor efeitos de cache",
+	}, handleMemoryAccessBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "map_churn",
+		Description: "Cria um mapa com size chaves e aplica operations inserções e remoções intercaladas, determinístico pelo seed",
+	}, handleMapChurn)
+
+	addTool(server, &mcp.Tool{
+		Name:        "load_info",
+		Description: "Retorna a carga atual do servidor: requisições em andamento, latência média recente e uso de CPU desde a última consulta",
+	}, handleLoadInfo)
+
+	addTool(server, &mcp.Tool{
+		Name:        "error_bench",
+		Description: "Cria erros iterations vezes com fmt.Errorf, errors.New ou encapsulamento %w e retorna o custo por erro",
+	}, handleErrorBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "fetch_parallel",
+		Description: "Busca vários endpoints via HTTP GET com um pool limitado de workers e retorna status e tempo de cada um",
+	}, handleFetchParallel)
+
+	addTool(server, &mcp.Tool{
+		Name:        "reflect_bench",
+		Description: "Soma os campos de uma struct iterations vezes por acesso direto ou via reflect.Value.Field",
+	}, handleReflectBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "generate_csv",
+		Description: "Gera um CSV determinístico com rows linhas e cols colunas, incluindo campos que exigem aspas",
+	}, handleGenerateCSV)
+
+	addTool(server, &mcp.Tool{
+		Name:        "parse_csv",
+		Description: "Faz o parse de um CSV com encoding/csv e retorna contagens de linhas e campos e os erros de parse encontrados",
+	}, handleParseCSV)
+
+	addTool(server, &mcp.Tool{
+		Name:        "clock_bench",
+		Description: "Chama time.Now() iterations vezes e retorna o custo total e por chamada da leitura do relógio",
+	}, handleClockBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rng_bench",
+		Description: "Gera números aleatórios em concurrency goroutines com um *rand.Rand compartilhado sob mutex ou um por goroutine",
+	}, handleRNGBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "mandelbrot",
+		Description: "Calcula o número de iterações de escape de cada pixel do conjunto de Mandelbrot e retorna checksum e tempo, opcionalmente em paralelo",
+	}, handleMandelbrot)
+
+	addTool(server, &mcp.Tool{
+		Name:        "io_write_bench",
+		Description: "Escreve bytes em blocos de 64 bytes para io.Discard ou um arquivo temporário, direto ou via bufio.Writer",
+	}, handleIOWriteBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "counter_bench",
+		Description: "Incrementa um contador compartilhado em goroutines via mutex, atomic ou canal e confere se o total bate",
+	}, handleCounterBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "binary_serialize",
+		Description: "Serializa e desserializa data em gob ou JSON iterations vezes e retorna o tamanho e os tempos",
+	}, handleBinarySerialize)
+
+	addTool(server, &mcp.Tool{
+		Name:        "vector_sum",
+		Description: "Soma um []float64 de size elementos iterations vezes e retorna a soma, o tempo e a banda de memória",
+	}, handleVectorSum)
+
+	addTool(server, &mcp.Tool{
+		Name:        "tree_traversal",
+		Description: "Constrói uma árvore aleatória de nodes nós e a percorre de forma recursiva ou iterativa (pilha explícita)",
+	}, handleTreeTraversal)
+
+	addTool(server, &mcp.Tool{
+		Name:        "intern_bench",
+		Description: "Gera total strings a partir de unique valores distintos, com ou sem internamento em um mapa compartilhado, e retorna memória retida e tempo",
+	}, handleInternBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "sharded_map_bench",
+		Description: "Compara um mapa com um único mutex e um mapa dividido em shards sob acesso concorrente de goroutines goroutines",
+	}, handleShardedMapBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "json_decode_bench",
+		Description: "Decodifica data (JSON) com json.Unmarshal completo ou token a token com json.Decoder e retorna tempo, valores e campos",
+	}, handleJSONDecodeBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "bigmul_bench",
+		Description: "Multiplica inteiros grandes aleatórios de digits dígitos com math/big (mul, square ou exp, que eleva a 2^squarings) e retorna o tempo",
+	}, handleBigMulBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "pingpong",
+		Description: "Passa um token entre duas goroutines por dois canais sem buffer rounds vezes e retorna o custo da troca de contexto",
+	}, handlePingPong)
+
+	addTool(server, &mcp.Tool{
+		Name:        "byte_parse_bench",
+		Description: "Analisa campos inteiros separados por vírgula em data convertendo para string ou direto em []byte, iterations vezes, e retorna tempo e alocações",
+	}, handleByteParseBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "cache_sim",
+		Description: "Reproduz a sequência accesses em um cache de capacity entradas com política lru ou fifo e retorna a taxa de acerto",
+	}, handleCacheSim)
+
+	addTool(server, &mcp.Tool{
+		Name:        "strconv_bench",
+		Description: "Gera count strings de int, float ou bool e as analisa com strconv iterations vezes, retornando o tempo",
+	}, handleStrconvBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "sort_types",
+		Description: "Gera count valores int, float, string ou struct a partir de seed e os ordena com sort.Slice, retornando o tempo",
+	}, handleSortTypes)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rwmutex_bench",
+		Description: "Executa leitores e escritores sobre um valor protegido por sync.RWMutex e por sync.Mutex e retorna os tempos e as contagens",
+	}, handleRWMutexBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "fixture_json",
+		Description: "Gera um documento JSON determinístico de aproximadamente size_kb KB a partir de seed, para alimentar as outras ferramentas de JSON; size_kb é limitado para que o documento caiba em PROCESS_MAX_BYTES",
+	}, handleFixtureJSON)
+
+	addTool(server, &mcp.Tool{
+		Name:        "worker_pool_bench",
+		Description: "Executa tasks tarefas com um pool fixo de workers goroutines e com uma goroutine por tarefa, retornando o tempo de cada estratégia",
+	}, handleWorkerPoolBench)
+
+	addTool(server, &mcp.Tool{
+		Name:        "simulate_error",
+		Description: "Retorna sob demanda o erro indicado por code (tool_error ou um erro JSON-RPC como invalid_params) para testar o caminho de erro",
+	}, handleSimulateError)
+
+	addTool(server, &mcp.Tool{
+		Name:        "memcopy_bench",
+		Description: "Copia entre dois buffers de size_mb MB iterations vezes com copy() e retorna a vazão de memória",
+	}, handleMemcopyBench)
+
+	// Experimental tools are still being trialed or have process-wide side
+	// effects (the CPU profiler, forced GCs), so the default set leaves them out
+	addExperimentalTool(server, &mcp.Tool{
+		Name:        "profile_tool",
+		Description: "Executa uma ferramenta uma vez sob um perfil de CPU (runtime/pprof) e retorna o perfil em base64",
+	}, handleProfileTool)
+	addExperimentalTool(server, &mcp.Tool{
+		Name:        "finalizer_bench",
+		Description: "Aloca objects valores com runtime.SetFinalizer, força o GC e retorna quantos finalizadores rodaram e o tempo",
+	}, handleFinalizerBench)
+	addExperimentalTool(server, &mcp.Tool{
+		Name:        "syscall_bench",
+		Description: "Chama getpid iterations vezes e retorna o custo total e por chamada de sistema",
+	}, handleSyscallBench)
+
+	// Deliberately leaky tool, only registered when explicitly enabled
+	if cfg.EnableLeakTool {
+		addTool(server, &mcp.Tool{
+			Name:        "leak_memory",
+			Description: "Retém memória indefinidamente (vazamento proposital) para validar o monitoramento",
+		}, handleLeakMemory)
+	}
+
+	return server
+}
+
+// newHandler builds the HTTP routes (health, MCP, debug) for one server instance.
+// limiter may be nil when rate limiting is disabled.
+func newHandler(server *mcp.Server, limiter *rampLimiter, idempotency *idempotencyCache) http.Handler {
+	mux := http.NewServeMux()
+
 	// Health check endpoint (before HTTP handler)
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Write([]byte(`{"status":"ok","server_type":"go"}`))
 	})
@@ -195,11 +7685,252 @@ func main() {
 		return server
 	}, nil)
 
-	http.Handle("/mcp", httpHandler)
+	var mcpHandler http.Handler = idempotencyMiddleware(idempotency, frameValidationMiddleware(payloadMetricsMiddleware(recoverMiddleware(httpHandler))))
+	if cfg.ChunkSize > 0 {
+		// Outside the idempotency cache so replayed responses are fragmented too
+		mcpHandler = chunkingMiddleware(cfg.ChunkSize, time.Duration(cfg.ChunkDelayMs)*time.Millisecond, mcpHandler)
+	}
+	if limiter != nil {
+		mcpHandler = rateLimitMiddleware(limiter, mcpHandler)
+	}
+	mux.Handle("/mcp", streamShutdownMiddleware(mcpHandler))
+
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	mux.HandleFunc("POST /metrics/reset", handleMetricsReset)
+	if cfg.ReportSamples > 0 {
+		mux.HandleFunc("GET /report", handleReport)
+	}
+	mux.HandleFunc("GET /metrics/stream", handleMetricsStream)
+	mux.HandleFunc("GET /conns", handleConns)
+	mux.HandleFunc("GET /config", handleConfig)
 
-	fmt.Println("Go MCP server listening on port 8081")
-	fmt.Println("MCP endpoint: http://localhost:8081/mcp")
-	if err := http.ListenAndServe(":8081", nil); err != nil {
-		panic(err)
+	// Debug endpoints (pprof, forced GC) are opt-in so they never leak into normal runs
+	if cfg.EnableDebug {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.HandleFunc("POST /debug/gc", handleDebugGC)
+		mux.HandleFunc("POST /debug/gogc", handleDebugGOGC)
+	}
+
+	return mux
+}
+
+func main() {
+	// Validate configuration before binding the listener
+	var err error
+	cfg, err = loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	httpClient.Timeout = time.Duration(cfg.FetchTimeoutMs) * time.Millisecond
+	workQueue = make(chan string, cfg.QueueCap)
+	registerShutdownHook("cache", shutdownHookFunc(shutdownSharedCache))
+	registerShutdownHook("queue", shutdownHookFunc(shutdownWorkQueue))
+	if cfg.EnableDebug {
+		fmt.Println("Debug endpoints enabled: /debug/pprof/, /debug/gc, /debug/gogc")
+	}
+
+	// A single limiter is shared by every instance so RATE_LIMIT_RPS is process-wide
+	// (per client identity when RATE_LIMIT_SCOPE=client)
+	var limiter *rampLimiter
+	if cfg.RateLimitRPS > 0 {
+		limiter = newRampLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, time.Duration(cfg.RampSeconds)*time.Second, cfg.RateLimitScope == "client")
+		fmt.Printf("Rate limit: %d req/s (burst %d, ramp %ds, scope %s)\n", cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.RampSeconds, cfg.RateLimitScope)
+	}
+
+	// One independent MCP server + HTTP server per configured port
+	mcpServers := make([]*mcp.Server, len(cfg.Ports))
+	for i := range mcpServers {
+		mcpServers[i] = newMCPServer()
+	}
+	gated := make([]string, 0, len(experimentalTools))
+	for name := range experimentalTools {
+		gated = append(gated, name)
+	}
+	sort.Strings(gated)
+	if cfg.Experimental {
+		fmt.Printf("Experimental tools enabled (EXPERIMENTAL=1): %s\n", strings.Join(gated, ", "))
+	} else {
+		fmt.Printf("Experimental tools not registered, set EXPERIMENTAL=1 to enable: %s\n", strings.Join(gated, ", "))
+	}
+	var unknown []string
+	for name := range cfg.DisabledTools {
+		if !knownTools[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		// Not fatal: opt-in tools (e.g. leak_memory) are unknown unless their flag is set
+		sort.Strings(unknown)
+		fmt.Fprintf(os.Stderr, "Warning: DISABLED_TOOLS lists tools that are not registered: %s\n", strings.Join(unknown, ", "))
+	}
+	unknown = unknown[:0]
+	for name := range cfg.ToolDefaults {
+		if !knownTools[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		fmt.Fprintf(os.Stderr, "Warning: TOOL_DEFAULTS lists tools that are not registered: %s\n", strings.Join(unknown, ", "))
+	}
+	if len(cfg.ToolDefaults) > 0 {
+		withDefaults := make([]string, 0, len(cfg.ToolDefaults))
+		for name := range cfg.ToolDefaults {
+			withDefaults = append(withDefaults, name)
+		}
+		sort.Strings(withDefaults)
+		fmt.Printf("Tool defaults: %s\n", strings.Join(withDefaults, ", "))
+	}
+	if len(cfg.DisabledTools) > 0 {
+		disabled := make([]string, 0, len(cfg.DisabledTools))
+		for name := range cfg.DisabledTools {
+			disabled = append(disabled, name)
+		}
+		sort.Strings(disabled)
+		fmt.Printf("Disabled tools: %s\n", strings.Join(disabled, ", "))
+	}
+
+	logToolSchemas()
+
+	if cfg.EnableH2C {
+		fmt.Println("HTTP/2 cleartext (h2c) enabled")
+	}
+	if cfg.LogSampleRate > 0 {
+		fmt.Printf("Request logging: %.0f%% of successful requests sampled, failures always logged\n", cfg.LogSampleRate*100)
+	}
+	if cfg.ReportSamples > 0 {
+		fmt.Printf("Run report enabled: /report keeps the last %d latency samples per tool\n", cfg.ReportSamples)
+	}
+	if cfg.WriteTimeoutMs > 0 {
+		fmt.Printf("Write timeout: %dms per write, slow clients are disconnected\n", cfg.WriteTimeoutMs)
+	}
+	if cfg.ChunkSize > 0 {
+		fmt.Printf("Response fragmentation: %d-byte chunks, %dms apart\n", cfg.ChunkSize, cfg.ChunkDelayMs)
+	}
+	if cfg.MinResponseMs > 0 {
+		fmt.Printf("Minimum tool response time: %dms\n", cfg.MinResponseMs)
+	}
+	if cfg.MemSoftLimitMB > 0 {
+		go watchMemory(cfg.MemSoftLimitMB)
+		fmt.Printf("Memory soft limit: %d MB\n", cfg.MemSoftLimitMB)
+	}
+	if cfg.MaxGoroutines > 0 {
+		go watchGoroutines(cfg.MaxGoroutines)
+		fmt.Printf("Goroutine ceiling: %d\n", cfg.MaxGoroutines)
+	}
+	if cfg.MaxConns > 0 {
+		fmt.Printf("Max concurrent connections: %d per listener (excess connections wait)\n", cfg.MaxConns)
+	}
+
+	fmt.Printf("Socket options: TCP_NODELAY=%t SO_REUSEPORT=%t\n", cfg.TCPNoDelay, cfg.SOReusePort)
+	fmt.Printf("Fetch address guard: %s\n", cfg.FetchGuard)
+
+	idempotency := newIdempotencyCache(time.Duration(cfg.IdempotencyTTL) * time.Millisecond)
+	registerShutdownHook("idempotency", idempotency)
+
+	// Simulates a slow-initialising service: nothing listens until the delay ends
+	if cfg.StartupDelayMs > 0 {
+		fmt.Printf("Startup delay: waiting %dms before binding listeners\n", cfg.StartupDelayMs)
+		time.Sleep(time.Duration(cfg.StartupDelayMs) * time.Millisecond)
+	}
+
+	servers := make([]*http.Server, 0, len(cfg.Ports))
+	errCh := make(chan error, len(cfg.Ports))
+	for i, port := range cfg.Ports {
+		handler := inFlightMiddleware(newHandler(mcpServers[i], limiter, idempotency))
+		if cfg.WriteTimeoutMs > 0 {
+			handler = writeTimeoutMiddleware(time.Duration(cfg.WriteTimeoutMs)*time.Millisecond, handler)
+		}
+		if cfg.EnableH2C {
+			// Accepts both prior-knowledge h2c and HTTP/1.1 Upgrade; plain HTTP/1.1 still works
+			handler = h2c.NewHandler(handler, &http2.Server{})
+		}
+		srv := &http.Server{
+			Addr:      fmt.Sprintf(":%d", port),
+			Handler:   handler,
+			ConnState: conns.onStateChange,
+			BaseContext: func(net.Listener) context.Context {
+				return handlersCtx
+			},
+		}
+		srv.RegisterOnShutdown(cancelStreams)
+		servers = append(servers, srv)
+
+		lc := listenConfig()
+		ln, err := lc.Listen(context.Background(), "tcp", srv.Addr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "listener %s: %v\n", srv.Addr, err)
+			os.Exit(1)
+		}
+		if !cfg.TCPNoDelay {
+			ln = noDelayListener{Listener: ln, noDelay: false}
+		}
+		if cfg.MaxConns > 0 {
+			// Accept blocks once the cap is reached, until a connection closes
+			ln = netutil.LimitListener(ln, cfg.MaxConns)
+		}
+
+		go func() {
+			if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				errCh <- fmt.Errorf("listener %s: %w", srv.Addr, err)
+			}
+		}()
+		fmt.Printf("Go MCP server listening on port %d\n", port)
+		fmt.Printf("MCP endpoint: http://localhost:%d/mcp\n", port)
+	}
+
+	// Wait for a termination signal or a listener failure, then drain every instance
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	exitCode := 0
+	select {
+	case sig := <-sigCh:
+		fmt.Printf("Received %s, shutting down\n", sig)
+	case err := <-errCh:
+		fmt.Fprintln(os.Stderr, err)
+		exitCode = 1
+	}
+
+	grace := time.Duration(cfg.ShutdownGraceMs) * time.Millisecond
+	fmt.Printf("Draining %d in-flight requests (grace period %dms)\n", inFlight.Load(), cfg.ShutdownGraceMs)
+	drainStart := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				// Grace period expired: cancel what is still running and drop
+				// the remaining connections rather than exit under them
+				fmt.Fprintf(os.Stderr, "shutdown %s: %v, closing remaining connections\n", srv.Addr, err)
+				cancelHandlers()
+				srv.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	// Requests still counted here were cut off when the grace period expired
+	fmt.Printf("Drain finished in %.1fms of %dms grace, %d requests still in flight\n",
+		elapsedMs(drainStart), cfg.ShutdownGraceMs, inFlight.Load())
+	// Cut-off handlers had their context cancelled; hooks get their own
+	// deadline and wait within it for those handlers and any tool calls
+	// they started to return, so they don't race with a late enqueue or
+	// cache write
+	hookCtx, cancelHooks := context.WithTimeout(context.Background(), shutdownHookTimeout)
+	defer cancelHooks()
+	for inFlight.Load()+runningTools.Load() > 0 && hookCtx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := inFlight.Load() + runningTools.Load(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Running shutdown hooks with %d requests or tool calls still running\n", n)
 	}
+	runShutdownHooks(hookCtx)
+	os.Exit(exitCode)
 }
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHistogramRejectsUnrepresentableRanges(t *testing.T) {
+	cases := []struct {
+		name   string
+		values []float64
+	}{
+		{"single value lost to rounding", []float64{1e17}},
+		{"span overflows", []float64{-1e308, 1e308}},
+		{"nan", []float64{1, math.NaN()}},
+		{"inf", []float64{1, math.Inf(1)}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := handleHistogram(context.Background(), nil, HistogramArgs{Values: tc.values, Buckets: 10})
+			if err == nil {
+				t.Fatalf("expected error for %v", tc.values)
+			}
+		})
+	}
+}
+
+func TestHistogramCountsEveryValue(t *testing.T) {
+	values := []float64{-1e300, 0, 1e-300, 5e299, 1e300}
+	_, out, err := handleHistogram(context.Background(), nil, HistogramArgs{Values: values, Buckets: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, c := range out.Counts {
+		total += c
+	}
+	if total != len(values) {
+		t.Fatalf("counts sum to %d, want %d", total, len(values))
+	}
+}
+
+func TestRecoverToolTurnsPanicIntoError(t *testing.T) {
+	before := recoveredPanics.Load()
+	h := recoverTool("panics", func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, struct{}, error) {
+		panic("boom")
+	})
+	if _, _, err := h(context.Background(), nil, struct{}{}); err != errToolPanic {
+		t.Fatalf("err = %v, want errToolPanic", err)
+	}
+
+	raw := recoverRawTool("panics_raw", func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic("boom")
+	})
+	res, err := raw(context.Background(), nil)
+	if err != nil || res == nil || !res.IsError {
+		t.Fatalf("raw result = %+v, %v; want error result", res, err)
+	}
+	if got := recoveredPanics.Load() - before; got != 2 {
+		t.Fatalf("recoveredPanics grew by %d, want 2", got)
+	}
+}
+
+var registerTools sync.Once
+
+// registeredTools loads the default config and fills toolInvokers once, for
+// tests that call tools in-process
+func registeredTools(t *testing.T) {
+	t.Helper()
+	registerTools.Do(func() {
+		var err error
+		if cfg, err = loadConfig(); err != nil {
+			t.Fatal(err)
+		}
+		newMCPServer()
+	})
+}
+
+func TestProfileToolAppliesGuardsToTarget(t *testing.T) {
+	registeredTools(t)
+	goroutinesDegraded.Store(true)
+	defer goroutinesDegraded.Store(false)
+
+	_, _, err := handleProfileTool(context.Background(), nil, ProfileToolArgs{Tool: "spawn_goroutines"})
+	var rpcErr *jsonrpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codeServerOverloaded {
+		t.Fatalf("err = %v, want overloaded error", err)
+	}
+}
+
+func TestBatchAppliesGuardsToNestedCalls(t *testing.T) {
+	registeredTools(t)
+	goroutinesDegraded.Store(true)
+	defer goroutinesDegraded.Store(false)
+
+	args := BatchArgs{Calls: []BatchCall{
+		{Tool: "spawn_goroutines", Args: map[string]interface{}{"count": 10}},
+		{Tool: "calculate_fibonacci", Args: map[string]interface{}{"n": 10}},
+	}}
+	_, out, err := handleBatch(context.Background(), nil, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Results[0].Ok || !out.Results[1].Ok {
+		t.Fatalf("results = %+v, want only the goroutine-spawning call rejected", out.Results)
+	}
+}
+
+func TestFrameValidationRejectsOversizedBody(t *testing.T) {
+	old := cfg.MaxBodyBytes
+	cfg.MaxBodyBytes = 64
+	defer func() { cfg.MaxBodyBytes = old }()
+
+	h := frameValidationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("oversized body reached the next handler")
+	}))
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping","params":{"pad":"` + strings.Repeat("x", 100) + `"}}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", rec.Code)
+	}
+}
+
+// idempotentPost sends body to h under an Idempotency-Key
+func idempotentPost(h http.Handler, session, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	req.Header.Set("Mcp-Session-Id", session)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// newIdempotencyTest wraps next in a fresh idempotency cache with a body
+// limit large enough for the tests
+func newIdempotencyTest(t *testing.T, next http.Handler) http.Handler {
+	t.Helper()
+	old := cfg.MaxBodyBytes
+	cfg.MaxBodyBytes = 64 << 20
+	c := newIdempotencyCache(time.Minute)
+	t.Cleanup(func() {
+		c.Shutdown(context.Background())
+		cfg.MaxBodyBytes = old
+	})
+	return idempotencyMiddleware(c, next)
+}
+
+func TestIdempotencyReplaysAndRejectsMismatchedBody(t *testing.T) {
+	var calls atomic.Int64
+	h := newIdempotencyTest(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "call %d", calls.Add(1))
+	}))
+
+	first := idempotentPost(h, "s1", "k", `{"id":1}`)
+	replay := idempotentPost(h, "s1", "k", `{"id":1}`)
+	if calls.Load() != 1 || replay.Header().Get("Idempotent-Replayed") != "true" || replay.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %q after %d calls, want %q replayed from one call", replay.Code, replay.Body, calls.Load(), first.Body)
+	}
+	if rec := idempotentPost(h, "s1", "k", `{"id":2}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key with a different body: status = %d, want 422", rec.Code)
+	}
+	if rec := idempotentPost(h, "s2", "k", `{"id":1}`); rec.Header().Get("Idempotent-Replayed") != "" || calls.Load() != 2 {
+		t.Fatal("another session's request was replayed")
+	}
+}
+
+func TestIdempotencyDuplicateWaitsForFirst(t *testing.T) {
+	var calls atomic.Int64
+	started, release := make(chan struct{}), make(chan struct{})
+	h := newIdempotencyTest(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	}))
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- idempotentPost(h, "s", "k", "{}") }()
+	<-started
+	second := make(chan *httptest.ResponseRecorder)
+	go func() { second <- idempotentPost(h, "s", "k", "{}") }()
+	select {
+	case <-second:
+		t.Fatal("duplicate finished before the original request")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-first
+	rec := <-second
+	if calls.Load() != 1 || rec.Header().Get("Idempotent-Replayed") != "true" || rec.Body.String() != "done" {
+		t.Fatalf("duplicate = %q (replayed %q) after %d calls, want a replay of the one call", rec.Body, rec.Header().Get("Idempotent-Replayed"), calls.Load())
+	}
+}
+
+func TestIdempotencySkipsFailedAndOversizedResponses(t *testing.T) {
+	cases := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+	}{
+		{"5xx", func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) }},
+		{"oversized", func(w http.ResponseWriter) { w.Write(make([]byte, idempotencyMaxResponseBytes+1)) }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int64
+			h := newIdempotencyTest(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				tc.write(w)
+			}))
+			idempotentPost(h, "s", "k", "{}")
+			if rec := idempotentPost(h, "s", "k", "{}"); rec.Header().Get("Idempotent-Replayed") != "" || calls.Load() != 2 {
+				t.Fatalf("retry was replayed (%d calls), want a fresh attempt", calls.Load())
+			}
+		})
+	}
+}
+
+func TestFetchAddressGuard(t *testing.T) {
+	old := cfg.FetchGuard
+	defer func() { cfg.FetchGuard = old }()
+
+	cases := []struct {
+		guard, ip string
+		allowed   bool
+	}{
+		{"standard", "93.184.216.34", true},
+		{"standard", "172.18.0.5", true},
+		{"standard", "127.0.0.1", false},
+		{"standard", "::1", false},
+		{"standard", "169.254.169.254", false},
+		{"standard", "0.0.0.0", false},
+		{"strict", "172.18.0.5", false},
+		{"strict", "93.184.216.34", true},
+		{"off", "127.0.0.1", true},
+	}
+	for _, tc := range cases {
+		cfg.FetchGuard = tc.guard
+		if got := fetchAddressAllowed(net.ParseIP(tc.ip)); got != tc.allowed {
+			t.Errorf("FETCH_GUARD=%s %s: allowed = %t, want %t", tc.guard, tc.ip, got, tc.allowed)
+		}
+	}
+}
+
+func TestFetchOneSharesBreakerAndRedirectLimit(t *testing.T) {
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg.FetchGuard = "off"
+	cfg.CircuitFailures = 1
+	cfg.CircuitCooldown = 60000
+
+	loop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/again", http.StatusFound)
+	}))
+	defer loop.Close()
+	if r := fetchOne(context.Background(), loop.URL); r.ErrorKind != "too_many_redirects" || r.Redirects != defaultMaxRedirects {
+		t.Fatalf("redirect loop = %+v, want too_many_redirects after %d", r, defaultMaxRedirects)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if r := fetchOne(context.Background(), failing.URL); r.StatusCode != http.StatusInternalServerError || r.CircuitOpen {
+		t.Fatalf("first fetch = %+v, want a 500 through a closed circuit", r)
+	}
+	if r := fetchOne(context.Background(), failing.URL); !r.CircuitOpen || r.ErrorKind != "circuit_open" {
+		t.Fatalf("second fetch = %+v, want circuit_open", r)
+	}
+	circuitRecord(strings.TrimPrefix(failing.URL, "http://"), false)
+}
+
+func TestLargestFixtureFitsProcessLimits(t *testing.T) {
+	registeredTools(t)
+	_, fixture, err := handleFixtureJSON(context.Background(), nil, FixtureJSONArgs{SizeKB: fixtureMaxKB(), Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(ProcessDataArgs{Data: fixture.Data, PreciseNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "process_json_data", Arguments: raw}}
+	if _, err := handleProcessDataRaw(context.Background(), req); err != nil {
+		t.Fatalf("process_json_data rejected a %d KB fixture: %v", fixture.SizeKB, err)
+	}
+	if _, _, err := handleFixtureJSON(context.Background(), nil, FixtureJSONArgs{SizeKB: fixtureMaxKB() + 1}); err == nil {
+		t.Fatal("size_kb above PROCESS_MAX_BYTES was accepted")
+	}
+}
+
+func TestFetchCacheTTLDirectives(t *testing.T) {
+	old := cfg.FetchCacheTTL
+	cfg.FetchCacheTTL = 5000
+	defer func() { cfg.FetchCacheTTL = old }()
+
+	cases := []struct {
+		headers []string
+		want    time.Duration
+	}{
+		{nil, 5 * time.Second},
+		{[]string{"max-age=60"}, 60 * time.Second},
+		{[]string{"public, MAX-AGE=0"}, 0},
+		{[]string{"max-age=60, no-store"}, 0},
+		{[]string{"no-cache, max-age=60"}, 0},
+		{[]string{"max-age=60", "no-store"}, 0},
+		{[]string{`no-cache="Set-Cookie", max-age=60`}, 0},
+		{[]string{"max-age=30, max-age=90"}, 30 * time.Second},
+		{[]string{"max-age=abc"}, 5 * time.Second},
+		{[]string{"max-age=-1"}, 5 * time.Second},
+	}
+	for _, tc := range cases {
+		resp := &http.Response{Header: http.Header{}}
+		for _, h := range tc.headers {
+			resp.Header.Add("Cache-Control", h)
+		}
+		if got := fetchCacheTTL(resp); got != tc.want {
+			t.Errorf("Cache-Control %q: ttl = %v, want %v", tc.headers, got, tc.want)
+		}
+	}
+}
+
+func TestWorkerPoolBenchStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := handleWorkerPoolBench(ctx, nil, WorkerPoolBenchArgs{Tasks: 30, Workers: 1, WorkMs: 1000})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("cancelled bench ran for %v", elapsed)
+	}
+}
+
+func TestQuicksortSortsWithAbsoluteIndices(t *testing.T) {
+	xs := []float64{5, 3, 9, 1, 1, 8, 2, 7, 6, 0, 4}
+	swap := func(i, j int) { xs[i], xs[j] = xs[j], xs[i] }
+	quicksort(xs, 0, len(xs)-1, func(a, b float64) bool { return a < b }, swap)
+	for i := 1; i < len(xs); i++ {
+		if xs[i-1] > xs[i] {
+			t.Fatalf("not sorted: %v", xs)
+		}
+	}
+}
+
+func TestParseFieldsModesAgreeOnOverflow(t *testing.T) {
+	data := []byte("9223372036854775807, -9223372036854775808,9223372036854775808,-9223372036854775809,99999999999999999999,+12,-,x1, 7")
+	sf, sn, ss := parseFieldsString(data)
+	bf, bn, bs := parseFieldsBytes(data)
+	if sf != bf || sn != bn || ss != bs {
+		t.Fatalf("string mode = (%d, %d, %d), bytes mode = (%d, %d, %d)", sf, sn, ss, bf, bn, bs)
+	}
+}
+
+func TestWriteTimeoutClosesSlowReader(t *testing.T) {
+	writeErr := make(chan error, 1)
+	chunk := bytes.Repeat([]byte("x"), 64<<10)
+	handler := writeTimeoutMiddleware(100*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Keep writing until the stalled client fills every socket buffer
+		// and a write misses its deadline
+		for written := 0; written < 1<<30; written += len(chunk) {
+			if _, err := w.Write(chunk); err != nil {
+				writeErr <- err
+				return
+			}
+			w.(http.Flusher).Flush()
+		}
+		writeErr <- nil
+	}))
+	closed := make(chan struct{})
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			close(closed)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	before := writeTimeouts.Load()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.(*net.TCPConn).SetReadBuffer(4096)
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The client never reads, so the server must give up on it by itself
+	select {
+	case err := <-writeErr:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("handler write error = %v, want deadline exceeded", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server never timed out writing to the stalled client")
+	}
+	if got := writeTimeouts.Load() - before; got != 1 {
+		t.Fatalf("writeTimeouts grew by %d, want 1", got)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("server kept the connection open after the write timeout")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import "errors"
+
+// reusePortSupported reports whether SO_REUSEPORT=true can be honoured here
+const reusePortSupported = false
+
+// setReusePort is never reached: loadConfig rejects SO_REUSEPORT=true on
+// platforms without the option
+func setReusePort(fd uintptr) error {
+	return errors.New("SO_REUSEPORT não é suportado nesta plataforma")
+}
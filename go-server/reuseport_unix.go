@@ -0,0 +1,14 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// reusePortSupported reports whether SO_REUSEPORT=true can be honoured here
+const reusePortSupported = true
+
+// setReusePort sets SO_REUSEPORT on a listening socket, using this
+// platform's value for the option
+func setReusePort(fd uintptr) error {
+	return unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+}